@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+var allComponents struct {
+	api       bool
+	outbox    bool
+	worker    bool
+	scheduler bool
+}
+
+var allCmd = &cobra.Command{
+	Use:   "all",
+	Short: "Runs the API server, outbox worker and asynq worker in one process",
+	Run: func(cmd *cobra.Command, args []string) {
+		lc := newAppLifecycle()
+
+		// consumers start first and the API last, so stopping in reverse order
+		// stops taking requests before the relay and the worker drain
+		if allComponents.worker {
+			StartWorkerService(lc)
+			StartWorker(lc)
+		}
+		if allComponents.outbox {
+			StartOutboxWorker(lc)
+		}
+		if allComponents.scheduler {
+			StartScheduler(lc)
+		}
+		if allComponents.api {
+			NewServer(lc)
+		}
+
+		if len(lc.hooks) == 0 {
+			log.Fatal("all: every component is disabled")
+		}
+
+		if err := lc.Run(); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	allCmd.Flags().BoolVar(&allComponents.api, "api", true, "run the API server")
+	allCmd.Flags().BoolVar(&allComponents.outbox, "outbox", true, "run the outbox worker")
+	allCmd.Flags().BoolVar(&allComponents.worker, "worker", true, "run the asynq worker and its monitoring server")
+	allCmd.Flags().BoolVar(&allComponents.scheduler, "scheduler", false, "run the cron scheduler")
+}
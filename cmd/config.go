@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	goErrors "errors"
+	"eventdrivensystem/configs"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspects the configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Loads the configuration like the other commands do and reports every invalid field",
+	Run: func(cmd *cobra.Command, args []string) {
+		profile := os.Getenv(configs.EnvProfile)
+		_, err := configs.LoadFrom(configs.DefaultSources(configFile, profile)...)
+
+		var verr *configs.ValidationError
+		switch {
+		case goErrors.As(err, &verr):
+			for _, field := range verr.Fields {
+				fmt.Println(field)
+			}
+			os.Exit(1)
+		case err != nil:
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		fmt.Println("config is valid")
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"eventdrivensystem/configs"
 	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/health"
 	"eventdrivensystem/pkg/logger"
 	"log"
 	"runtime"
@@ -39,7 +41,7 @@ func NewAppDependency() *AppDependency {
 	lgOptions := logger.Options{
 		Output:    logger.OutputStdout,
 		Formatter: logger.FormatJSON,
-		Level:     logger.LevelInfo,
+		Level:     logLevel(cfg),
 		DefaultFields: map[string]string{
 			"app.name":    cfg.Meta.Name,
 			"app.runtime": runtime.Version(),
@@ -50,6 +52,14 @@ func NewAppDependency() *AppDependency {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
 
+	configs.OnReload(func(c *configs.AppConfig) {
+		if level := logLevel(c); level != lgOptions.Level {
+			lgOptions.Level = level
+			lg.SetOptions(lgOptions)
+		}
+	})
+	configs.Watch(context.Background())
+
 	asynqClient := asynq.NewClient(asynq.RedisClientOpt{Addr: cfg.Redis.Address})
 
 	return &AppDependency{
@@ -60,3 +70,18 @@ func NewAppDependency() *AppDependency {
 		validator: goValidator.New(),
 	}
 }
+
+func logLevel(cfg *configs.AppConfig) string {
+	if cfg.Log.Level == "" {
+		return logger.LevelInfo
+	}
+	return cfg.Log.Level
+}
+
+// newHealthChecker checks the dependencies every process shares
+func newHealthChecker(dp *AppDependency) *health.Checker {
+	checker := health.NewChecker()
+	checker.Add("postgres", health.Database(dp.db))
+	checker.Add("redis", health.Redis(dp.queue))
+	return checker
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"eventdrivensystem/pkg/logger"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Hook is one component of a process. OnStart must not block, long running work
+// goes to a goroutine that reports an unexpected exit through Lifecycle.Fail.
+// OnStop stops what OnStart started and should return once ctx is done.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle starts hooks in the order they were appended and stops them in
+// reverse order on SIGINT, SIGTERM or the first failure, all stops share one deadline.
+type Lifecycle struct {
+	hooks           []Hook
+	shutdownTimeout time.Duration
+	lg              logger.Logger
+	failed          chan error
+}
+
+func NewLifecycle(lg logger.Logger, shutdownTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{
+		shutdownTimeout: shutdownTimeout,
+		lg:              lg,
+		failed:          make(chan error, 1),
+	}
+}
+
+// newAppLifecycle is the lifecycle of the commands, configured from AppDependency
+func newAppLifecycle() *Lifecycle {
+	dp := GetAppDependency()
+	return NewLifecycle(dp.log, time.Duration(dp.cfg.Lifecycle.ShutdownTimeoutInSeconds)*time.Second)
+}
+
+func (l *Lifecycle) Append(hook Hook) {
+	l.hooks = append(l.hooks, hook)
+}
+
+// Fail shuts the process down, only the first failure is kept
+func (l *Lifecycle) Fail(name string, err error) {
+	select {
+	case l.failed <- fmt.Errorf("%s: %w", name, err):
+	default:
+	}
+}
+
+// Run starts the hooks, blocks until a signal or a failure and stops the started
+// hooks. It returns the start or run failure, or else the first stop error.
+func (l *Lifecycle) Run() error {
+	signalCtx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	started := 0
+	for _, hook := range l.hooks {
+		if hook.OnStart != nil {
+			if err = hook.OnStart(context.Background()); err != nil {
+				err = fmt.Errorf("start %s: %w", hook.Name, err)
+				break
+			}
+		}
+		l.lg.Info("[LIFECYCLE] started " + hook.Name)
+		started++
+	}
+
+	if err == nil {
+		select {
+		case <-signalCtx.Done():
+			l.lg.Info("[LIFECYCLE] received shutdown signal, stopping")
+		case err = <-l.failed:
+			l.lg.Error("[LIFECYCLE] stopping after failure: ", err)
+		}
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	for i := started - 1; i >= 0; i-- {
+		hook := l.hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		if stopErr := hook.OnStop(stopCtx); stopErr != nil {
+			l.lg.Error("[LIFECYCLE] error stopping "+hook.Name+": ", stopErr)
+			if err == nil {
+				err = fmt.Errorf("stop %s: %w", hook.Name, stopErr)
+			}
+			continue
+		}
+		l.lg.Info("[LIFECYCLE] stopped " + hook.Name)
+	}
+
+	return err
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	goErrors "errors"
+	"eventdrivensystem/pkg/logger"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func newTestLifecycle(timeout time.Duration) *Lifecycle {
+	return NewLifecycle(logger.Init(logger.Options{Output: logger.OutputDiscard}), timeout)
+}
+
+func recordingHook(name string, calls *[]string) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestLifecycleStopsInReverseOrderAfterFailure(t *testing.T) {
+	lc := newTestLifecycle(time.Second)
+	var calls []string
+	failure := goErrors.New("port in use")
+
+	lc.Append(recordingHook("db", &calls))
+	lc.Append(recordingHook("server", &calls))
+	lc.Append(Hook{
+		Name: "relay",
+		OnStart: func(ctx context.Context) error {
+			go lc.Fail("relay", failure)
+			return nil
+		},
+	})
+
+	err := lc.Run()
+	assert.Assert(t, goErrors.Is(err, failure))
+	assert.DeepEqual(t, calls, []string{"start db", "start server", "stop server", "stop db"})
+}
+
+func TestLifecycleStopsOnlyStartedHooks(t *testing.T) {
+	lc := newTestLifecycle(time.Second)
+	var calls []string
+
+	lc.Append(recordingHook("db", &calls))
+	lc.Append(Hook{
+		Name:    "server",
+		OnStart: func(ctx context.Context) error { return goErrors.New("boom") },
+		OnStop: func(ctx context.Context) error {
+			calls = append(calls, "stop server")
+			return nil
+		},
+	})
+	lc.Append(recordingHook("relay", &calls))
+
+	err := lc.Run()
+	assert.ErrorContains(t, err, "start server: boom")
+	assert.DeepEqual(t, calls, []string{"start db", "stop db"})
+}
+
+func TestLifecycleShutdownTimeout(t *testing.T) {
+	lc := newTestLifecycle(10 * time.Millisecond)
+
+	lc.Append(Hook{
+		Name: "slow",
+		OnStart: func(ctx context.Context) error {
+			lc.Fail("slow", goErrors.New("stopped"))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+
+	start := time.Now()
+	assert.ErrorContains(t, lc.Run(), "slow: stopped")
+	assert.Assert(t, time.Since(start) < time.Second)
+}
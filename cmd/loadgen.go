@@ -0,0 +1,422 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"eventdrivensystem/internal/domain"
+	"eventdrivensystem/internal/events"
+	"eventdrivensystem/internal/loadgen"
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	notificationModels "eventdrivensystem/internal/models/notification"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	userModels "eventdrivensystem/internal/models/user"
+	"eventdrivensystem/internal/usecase"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/util"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	loadgenViaDB  = "db"
+	loadgenViaAPI = "api"
+
+	loadgenPassword = "Loadgen-Passw0rd!"
+)
+
+var loadgenOpts struct {
+	via         string
+	rate        int
+	duration    time.Duration
+	concurrency int
+	users       int
+	mix         string
+	apiURL      string
+	drain       time.Duration
+}
+
+var loadgenCmd = &cobra.Command{
+	Use:   "loadgen",
+	Short: "Generates users, notifications and outbox rows and reports end-to-end latencies",
+	Long: `Generates load at a fixed rate and reports the latency percentiles from creating
+an event to the outbox row being SENT and to the task being handled.
+
+With --via db the events are written through the domain layer. Notification,
+user_updated and onboarding_tip events go to the asynq queue "loadgen", which
+this command consumes itself to time the handling, user registrations run the
+real flows. With --via api users are registered through POST /api/v1/users.
+
+Run it against a test database, it reports every outbox row created while it runs.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if loadgenOpts.via == loadgenViaAPI && !cmd.Flags().Changed("mix") {
+			loadgenOpts.mix = loadgen.KindUser + "=1"
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		if err := RunLoadgen(ctx); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	f := loadgenCmd.Flags()
+	f.StringVar(&loadgenOpts.via, "via", loadgenViaDB, "db writes through the domain layer, api calls the REST API")
+	f.IntVar(&loadgenOpts.rate, "rate", 50, "events per second")
+	f.DurationVar(&loadgenOpts.duration, "duration", time.Minute, "how long to generate load")
+	f.IntVar(&loadgenOpts.concurrency, "concurrency", 8, "events created at the same time")
+	f.IntVar(&loadgenOpts.users, "users", 50, "users created up front that the db events belong to")
+	f.StringVar(&loadgenOpts.mix, "mix", "notification=60,user_updated=25,onboarding_tip=10,user=5", "weight of each kind: user, notification, user_updated, onboarding_tip, only user with --via api")
+	f.StringVar(&loadgenOpts.apiURL, "api-url", "", "base URL of the API server, defaults to ApiServer.Host and Port")
+	f.DurationVar(&loadgenOpts.drain, "drain", time.Minute, "how long to wait for the generated events to be handled")
+}
+
+type loadgenRunner struct {
+	db      *gorm.DB
+	dom     *domain.Domain
+	uc      *usecase.Usecase
+	tracker *loadgen.Tracker
+	client  *http.Client
+	apiURL  string
+	runID   string
+	users   []userModels.User
+	seq     atomic.Int64
+}
+
+func RunLoadgen(ctx context.Context) error {
+	dp := GetAppDependency()
+
+	allowed := []string{loadgen.KindUser, loadgen.KindNotification, loadgen.KindUserUpdated, loadgen.KindOnboardingTip}
+	if loadgenOpts.via == loadgenViaAPI {
+		allowed = []string{loadgen.KindUser}
+	} else if loadgenOpts.via != loadgenViaDB {
+		return fmt.Errorf("--via must be %s or %s", loadgenViaDB, loadgenViaAPI)
+	}
+
+	mix, err := loadgen.ParseMix(loadgenOpts.mix, allowed...)
+	if err != nil {
+		return err
+	}
+	if loadgenOpts.rate <= 0 || loadgenOpts.concurrency <= 0 {
+		return fmt.Errorf("--rate and --concurrency must be positive")
+	}
+
+	dom := domain.NewDomain(dp.cfg, dp.db, dp.log)
+	r := &loadgenRunner{
+		db:      dp.db,
+		dom:     dom,
+		uc:      usecase.NewUsecase(dp.cfg, dp.log, dom),
+		tracker: loadgen.NewTracker(),
+		client:  &http.Client{Timeout: 10 * time.Second},
+		apiURL:  loadgenOpts.apiURL,
+		runID:   time.Now().Format("20060102150405"),
+	}
+	if r.apiURL == "" {
+		r.apiURL = fmt.Sprintf("http://%s:%d", dp.cfg.ApiServer.Host, dp.cfg.ApiServer.Port)
+	}
+
+	start := time.Now()
+
+	if mix.Has(loadgen.KindNotification) || mix.Has(loadgen.KindUserUpdated) || mix.Has(loadgen.KindOnboardingTip) {
+		if err := r.createUsers(ctx, loadgenOpts.users); err != nil {
+			return err
+		}
+	}
+
+	server := asynq.NewServer(
+		asynq.RedisClientOpt{Addr: dp.cfg.Redis.Address},
+		asynq.Config{Queues: map[string]int{loadgen.Queue: 1}, Concurrency: loadgenOpts.concurrency},
+	)
+	if err := server.Start(asynq.HandlerFunc(r.handle)); err != nil {
+		return err
+	}
+	defer server.Shutdown()
+
+	created, failed := r.generate(ctx, mix)
+	log.Printf("generated %d events in %s, %d failed to be created", created, time.Since(start).Round(time.Second), failed)
+
+	r.waitForDrain(ctx, start)
+
+	return r.report(ctx, start)
+}
+
+// createUsers registers the users the db events refer to, through the usecase
+// so that their own registration events are part of the load
+func (r *loadgenRunner) createUsers(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("--users must be positive for db events")
+	}
+
+	for i := 0; i < n; i++ {
+		email := r.nextEmail()
+		if err := r.uc.User.CreateUser(ctx, &userModels.CreateUserParam{Email: email, Password: loadgenPassword}); err != nil {
+			return fmt.Errorf("create user %s: %w", email, err)
+		}
+
+		// read from the primary, a replica may not have the user yet
+		user, err := r.dom.User.GetUserByEmail(ctx, email, util.DbOptions{Clause: dbresolver.Write})
+		if err != nil {
+			return fmt.Errorf("read user %s: %w", email, err)
+		}
+		r.users = append(r.users, *user)
+	}
+
+	log.Printf("created %d users", n)
+	return nil
+}
+
+func (r *loadgenRunner) nextEmail() string {
+	return fmt.Sprintf("loadgen+%s-%d@example.com", r.runID, r.seq.Add(1))
+}
+
+// generate emits events at the configured rate until the duration is over or
+// ctx is done. A tick is skipped when every worker is still busy.
+func (r *loadgenRunner) generate(ctx context.Context, mix loadgen.Mix) (created, failed int64) {
+	// events already handed to a worker are still created after the duration
+	genCtx, cancel := context.WithTimeout(ctx, loadgenOpts.duration)
+	defer cancel()
+
+	jobs := make(chan string, loadgenOpts.concurrency)
+	wg := sync.WaitGroup{}
+	var skipped int64
+
+	for i := 0; i < loadgenOpts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for kind := range jobs {
+				if err := r.emit(ctx, kind); err != nil {
+					if ctx.Err() == nil {
+						log.Printf("create %s: %v", kind, err)
+					}
+					atomic.AddInt64(&failed, 1)
+					continue
+				}
+				atomic.AddInt64(&created, 1)
+			}
+		}()
+	}
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ticker := time.NewTicker(time.Second / time.Duration(loadgenOpts.rate))
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-genCtx.Done():
+			break loop
+		case <-ticker.C:
+			select {
+			case jobs <- mix.Pick(rnd):
+			default:
+				skipped++
+			}
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	if skipped > 0 {
+		log.Printf("skipped %d ticks because all %d workers were busy, raise --concurrency", skipped, loadgenOpts.concurrency)
+	}
+	return created, failed
+}
+
+func (r *loadgenRunner) emit(ctx context.Context, kind string) error {
+	if kind == loadgen.KindUser {
+		if loadgenOpts.via == loadgenViaAPI {
+			return r.registerThroughAPI(ctx)
+		}
+		return r.uc.User.CreateUser(ctx, &userModels.CreateUserParam{Email: r.nextEmail(), Password: loadgenPassword})
+	}
+
+	user := r.users[rand.Intn(len(r.users))]
+
+	var event events.Event
+	err := r.dom.UnitOfWork.Do(ctx, func(tx databases.Tx) error {
+		switch kind {
+		case loadgen.KindNotification:
+			notif, err := r.dom.Notification.CreateNotification(ctx, &notificationModels.Notification{
+				UserID:  user.ID,
+				Type:    notificationModels.NotificationTypeUserRegistration,
+				Message: notificationModels.NotificationMessageUserRegistration,
+				Status:  notificationModels.NotificationStatusPending,
+			}, tx.DbOptions())
+			if err != nil {
+				return err
+			}
+			event = &asynqModels.AsynqSendNotificationPayload{
+				NotificationID:   notif.ID,
+				UserID:           user.ID,
+				NotificationType: notif.Type,
+			}
+		case loadgen.KindUserUpdated:
+			event = &asynqModels.AsynqUserUpdatedPayload{AsynqUserEventPayload: asynqModels.AsynqUserEventPayload{
+				UserID:        user.ID,
+				Email:         user.Email,
+				ChangedFields: []string{"display_name"},
+				OccurredAt:    time.Now().UTC(),
+			}}
+		case loadgen.KindOnboardingTip:
+			event = &asynqModels.AsynqSendOnboardingTipPayload{UserID: user.ID, Email: user.Email}
+		}
+
+		return r.dom.Events.Publish(ctx, tx, event, events.WithTopic(loadgen.Queue))
+	})
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	key, err := loadgen.EventKey(event.EventType(), payload)
+	if err != nil {
+		return err
+	}
+	r.tracker.Created(key, time.Now())
+	return nil
+}
+
+func (r *loadgenRunner) registerThroughAPI(ctx context.Context) error {
+	body, err := json.Marshal(map[string]string{"email": r.nextEmail(), "password": loadgenPassword})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.apiURL+"/api/v1/users", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("POST /api/v1/users: %s", resp.Status)
+	}
+	return nil
+}
+
+// handle consumes the loadgen queue and records when each event was handled
+func (r *loadgenRunner) handle(ctx context.Context, task *asynq.Task) error {
+	key, err := loadgen.EventKey(task.Type(), task.Payload())
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
+
+	r.tracker.Handled(task.Type(), key, time.Now())
+	return nil
+}
+
+// waitForDrain waits until every created event was handled and no outbox row of
+// the run is waiting to be relayed, or until --drain is over
+func (r *loadgenRunner) waitForDrain(ctx context.Context, start time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, loadgenOpts.drain)
+	defer cancel()
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		var unrelayed int64
+		err := r.db.WithContext(ctx).Clauses(dbresolver.Write).Model(&outboxModels.Outbox{}).
+			Where("created_at >= ? AND status IN ?", start, []string{
+				outboxModels.OutboxStatusPending, outboxModels.OutboxStatusRetrying, outboxModels.OutboxStatusProcessing,
+			}).
+			Where("execute_at <= ?", time.Now()).
+			Count(&unrelayed).Error
+
+		if err == nil && unrelayed == 0 && r.tracker.Pending() == 0 {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("stopped waiting after %s: %d rows not relayed, %d tasks not handled", loadgenOpts.drain, unrelayed, r.tracker.Pending())
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type loadgenOutboxRow struct {
+	EventType string
+	Status    string
+	CreatedAt time.Time
+	SentAt    *time.Time
+}
+
+// report prints, by event type, how the outbox rows of the run ended up and the
+// create to SENT and create to handled latency percentiles
+func (r *loadgenRunner) report(ctx context.Context, start time.Time) error {
+	var rows []loadgenOutboxRow
+	err := r.db.WithContext(ctx).Clauses(dbresolver.Write).Model(&outboxModels.Outbox{}).
+		Select("event_type, status, created_at, sent_at").
+		Where("created_at >= ?", start).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	type stats struct {
+		statuses map[string]int
+		sent     []time.Duration
+	}
+	byType := map[string]*stats{}
+	for _, row := range rows {
+		s, ok := byType[row.EventType]
+		if !ok {
+			s = &stats{statuses: map[string]int{}}
+			byType[row.EventType] = s
+		}
+		s.statuses[row.Status]++
+		if row.SentAt != nil {
+			s.sent = append(s.sent, row.SentAt.Sub(row.CreatedAt))
+		}
+	}
+
+	handled := r.tracker.Latencies()
+
+	eventTypes := make([]string, 0, len(byType))
+	for eventType := range byType {
+		eventTypes = append(eventTypes, eventType)
+	}
+	sort.Strings(eventTypes)
+
+	fmt.Fprintf(os.Stdout, "\n%d outbox rows since %s\n", len(rows), start.Format(time.RFC3339))
+	for _, eventType := range eventTypes {
+		s := byType[eventType]
+		fmt.Fprintf(os.Stdout, "\n%s %v\n", eventType, s.statuses)
+		fmt.Fprintf(os.Stdout, "  create -> SENT     %s\n", loadgen.Summarize(s.sent))
+		if l, ok := handled[eventType]; ok {
+			fmt.Fprintf(os.Stdout, "  create -> handled  %s\n", loadgen.Summarize(l))
+		}
+	}
+
+	return nil
+}
@@ -16,12 +16,26 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+var configFile string
+
 func init() {
-	configs.Load()
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", configs.DefaultConfigFile, "base config file, "+configs.EnvProfile+" layers config.<env>.yml over it")
+
+	// runs after the flags are parsed and before any command, the config itself
+	// is loaded on first use so that `config validate` can report a broken one
+	cobra.OnInitialize(func() {
+		configs.SetConfigFile(configFile)
+	})
+
 	rootCmd.AddCommand(apiServerCmd)
 	rootCmd.AddCommand(migrateUpCmd)
+	rootCmd.AddCommand(migrateCmd)
 	rootCmd.AddCommand(outboxWorkerCmd)
 	rootCmd.AddCommand(asynqWorkerCmd)
+	rootCmd.AddCommand(schedulerCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(allCmd)
+	rootCmd.AddCommand(loadgenCmd)
 }
 
 func Execute() {
@@ -4,38 +4,273 @@ import (
 	"errors"
 	"eventdrivensystem/configs"
 	"eventdrivensystem/pkg/databases"
+	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/spf13/cobra"
 )
 
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manages the db migrations",
+}
+
+// migrateUpCmd is kept for the Makefile and existing deploy scripts
 var migrateUpCmd = &cobra.Command{
-	Use:   "db-migrate-up",
-	Short: "Runs the db migrations",
+	Use:        "db-migrate-up",
+	Short:      "Runs the db migrations",
+	Deprecated: "use migrate up",
 	Run: func(cmd *cobra.Command, args []string) {
-		MigrateUp()
+		MigrateUp(0)
 	},
 }
 
-func MigrateUp() {
-	cfg := configs.Get()
-	m, err := databases.NewMigrate(cfg)
+var migrateUpSubCmd = &cobra.Command{
+	Use:   "up [N]",
+	Short: "Applies all pending migrations, or the next N",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		n := 0
+		if len(args) == 1 {
+			n = parsePositive(args[0])
+		}
+		MigrateUp(n)
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down N",
+	Short: "Reverts the last N applied migrations",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		n := parsePositive(args[0])
+		runMigration(func(m *migrate.Migrate) error { return m.Steps(-n) })
+	},
+}
+
+var migrateGotoCmd = &cobra.Command{
+	Use:   "goto V",
+	Short: "Migrates up or down to version V",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		v := parsePositive(args[0])
+		runMigration(func(m *migrate.Migrate) error { return m.Migrate(uint(v)) })
+	},
+}
+
+var migrateForceCmd = &cobra.Command{
+	Use:   "force V",
+	Short: "Sets the version to V and clears the dirty flag without running any migration",
+	Long: "Sets the version to V and clears the dirty flag without running any migration. " +
+		"After a failed migration fix the database by hand and force the version it is now at.",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		v, err := strconv.Atoi(args[0])
+		if err != nil || v < -1 {
+			log.Fatalf("invalid version %q, use -1 for no version", args[0])
+		}
+		runMigration(func(m *migrate.Migrate) error { return m.Force(v) })
+	},
+}
+
+var migrateVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Prints the current version and whether it is dirty",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		m := newMigrate()
+		defer m.Close()
+
+		version, dirty, err := m.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			fmt.Println("no migration applied")
+			return
+		}
+		if err != nil {
+			log.Fatalf("failed to read version: %v", err)
+		}
+
+		fmt.Println(formatVersion(version, dirty))
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Lists every migration and whether it is applied",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		MigrateStatus()
+	},
+}
+
+var migrateCreateDir string
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Creates the next numbered up and down migration files",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		files, err := createMigration(migrateCreateDir, args[0])
+		if err != nil {
+			log.Fatalf("failed to create migration: %v", err)
+		}
+		for _, f := range files {
+			fmt.Println(f)
+		}
+	},
+}
+
+func init() {
+	migrateCreateCmd.Flags().StringVar(&migrateCreateDir, "dir", "docs/db/migrations", "directory of the migration files")
+
+	migrateCmd.AddCommand(migrateUpSubCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateGotoCmd)
+	migrateCmd.AddCommand(migrateForceCmd)
+	migrateCmd.AddCommand(migrateVersionCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateCreateCmd)
+}
+
+func newMigrate() *migrate.Migrate {
+	m, err := databases.NewMigrate(configs.Get())
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
+	return m
+}
 
-	err = m.Up()
-	if err != nil {
+// runMigration runs fn and reports the version the database is at afterwards
+func runMigration(fn func(m *migrate.Migrate) error) {
+	m := newMigrate()
+	defer m.Close()
+
+	if err := fn(m); err != nil {
 		if errors.Is(err, migrate.ErrNoChange) {
 			log.Printf("no migrations to run -> %v", err)
-		} else {
-			log.Fatalf("failed to run migrations: %v", err)
+			return
 		}
-		return
+		log.Fatalf("failed to run migrations: %v", err)
 	}
 
+	if version, dirty, err := m.Version(); err == nil {
+		log.Println("migrations run successfully, now at " + formatVersion(version, dirty))
+		return
+	}
 	log.Println("migrations run successfully")
+}
+
+// MigrateUp applies the next n migrations, all pending ones when n is 0
+func MigrateUp(n int) {
+	runMigration(func(m *migrate.Migrate) error {
+		if n > 0 {
+			return m.Steps(n)
+		}
+		return m.Up()
+	})
+}
+
+func MigrateStatus() {
+	cfg := configs.Get()
+	m := newMigrate()
+	defer m.Close()
+
+	current, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		log.Fatalf("failed to read version: %v", err)
+	}
+	applied := err == nil
+
+	src, err := databases.NewMigrationSource(cfg.SQL.MigrationsPath)
+	if err != nil {
+		log.Fatalf("failed to open migrations: %v", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	for err == nil {
+		r, name, readErr := src.ReadUp(version)
+		if readErr != nil {
+			log.Fatalf("failed to read migration %d: %v", version, readErr)
+		}
+		r.Close()
+
+		state := "pending"
+		switch {
+		case applied && version == current && dirty:
+			state = "dirty"
+		case applied && version <= current:
+			state = "applied"
+		}
+		fmt.Printf("%06d  %-8s %s\n", version, state, name)
+
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		log.Fatalf("failed to list migrations: %v", err)
+	}
+}
+
+func formatVersion(version uint, dirty bool) string {
+	if dirty {
+		return fmt.Sprintf("version %d (dirty)", version)
+	}
+	return fmt.Sprintf("version %d", version)
+}
+
+func parsePositive(arg string) int {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n <= 0 {
+		log.Fatalf("invalid number %q, it must be a positive integer", arg)
+	}
+	return n
+}
+
+var (
+	migrationFile = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
+	migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// createMigration writes empty up and down files numbered after the highest
+// existing migration in dir, like `migrate create -seq -digits 6` does
+func createMigration(dir, name string) ([]string, error) {
+	if !migrationName.MatchString(name) {
+		return nil, fmt.Errorf("name %q must be lower case letters, digits and underscores", name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	next := 1
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		if v, _ := strconv.Atoi(match[1]); v >= next {
+			next = v + 1
+		}
+	}
+
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", next, name, direction))
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err != nil {
+			return files, err
+		}
+		f.Close()
+		files = append(files, path)
+	}
 
+	return files, nil
 }
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCreateMigrationNumbersAfterTheHighest(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "000009_create_table_a.up.sql"), nil, 0o644))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "000011_create_table_b.down.sql"), nil, 0o644))
+
+	files, err := createMigration(dir, "add_index")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{
+		filepath.Join(dir, "000012_add_index.up.sql"),
+		filepath.Join(dir, "000012_add_index.down.sql"),
+	})
+
+	_, err = createMigration(dir, "Add Index")
+	assert.ErrorContains(t, err, "must be lower case")
+}
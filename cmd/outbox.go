@@ -2,276 +2,531 @@ package cmd
 
 import (
 	"context"
+	goErrors "errors"
 	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/destinations"
+	"eventdrivensystem/internal/domain/outbox"
 	models "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/health"
 	"eventdrivensystem/pkg/logger"
 	"eventdrivensystem/pkg/util"
 	"fmt"
 	"log"
-	"math/rand"
+	"net/http"
 	"os"
-	"os/signal"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/go-openapi/strfmt"
 	"github.com/hibiken/asynq"
+	"github.com/labstack/echo/v4"
 	_ "github.com/lib/pq"
 	"github.com/spf13/cobra"
 	"gorm.io/gorm"
 )
 
+// outboxPublishTimeout bounds the publish of one batch, see minProcessingLease
+const outboxPublishTimeout = 30 * time.Second
+
 var outboxWorkerCmd = &cobra.Command{
 	Use:   "outbox-worker",
 	Short: "Runs the outbox worker",
 	Run: func(cmd *cobra.Command, args []string) {
-		outboxWorker := NewOutBoxWorker()
-
-		// Create a cancelable context for graceful shutdown
-		ctx, cancel := context.WithCancel(context.Background())
-		defer cancel() // Make sure to cancel the context when we're done
-
-		wg := &sync.WaitGroup{}
-		go outboxWorker.Run(ctx, wg)
+		lc := newAppLifecycle()
+		StartOutboxWorker(lc)
+		if err := lc.Run(); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
 
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		<-sigCh
+// StartOutboxWorker adds the outbox relay to lc. Stopping it ends the fetch loop
+// and then waits for the messages already handed to the worker pool.
+func StartOutboxWorker(lc *Lifecycle) {
+	outboxWorker := NewOutBoxWorker()
+	startOutboxHealthServer(lc, outboxWorker)
+	startOutboxAttemptPruner(lc)
+	startOutboxReaper(lc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	stopped := make(chan struct{})
+
+	lc.Append(Hook{
+		Name: "outbox-worker",
+		OnStart: func(context.Context) error {
+			go func() {
+				outboxWorker.Run(ctx, wg)
+				close(stopped)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+
+			drained := make(chan struct{})
+			go func() {
+				<-stopped
+				wg.Wait()
+				outboxWorker.results.Close()
+				close(drained)
+			}()
+
+			select {
+			case <-drained:
+				return nil
+			case <-stopCtx.Done():
+				return fmt.Errorf("outbox messages still in flight: %w", stopCtx.Err())
+			}
+		},
+	})
+}
 
-		log.Println("Received shutdown signal, initiating graceful shutdown...")
-		cancel() // Cancel the context to initiate a graceful shutdown
+// startOutboxHealthServer adds the health endpoints of the outbox worker to lc,
+// the relay is ready while its loop keeps iterating
+func startOutboxHealthServer(lc *Lifecycle, o *OutboxWorker) {
+	dp := GetAppDependency()
+	if dp.cfg.Outbox.HealthPort == 0 {
+		return
+	}
 
-		// Wait for all workers to finish
-		wg.Wait()
-		log.Println("Graceful shutdown complete.")
-	},
+	checker := newHealthChecker(dp)
+	checker.Add("relay", o.heartbeat.Check(func() time.Duration {
+		return time.Duration(configs.Current().Outbox.HeartbeatTimeoutInSeconds) * time.Second
+	}))
+
+	e := echo.New()
+	health.RegisterRoutes(e, checker)
+
+	lc.Append(Hook{
+		Name: "outbox-health",
+		OnStart: func(ctx context.Context) error {
+			address := fmt.Sprintf("%s:%d", dp.cfg.Outbox.HealthHost, dp.cfg.Outbox.HealthPort)
+			go func() {
+				if err := e.Start(address); err != nil && err != http.ErrServerClosed {
+					lc.Fail("outbox-health", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return e.Shutdown(ctx)
+		},
+	})
 }
 
 type OutboxWorker struct {
 	db         *gorm.DB
 	cfg        *configs.AppConfig
 	queue      *asynq.Client
-	workerPool chan bool
+	workerPool *workerPool
+	publisher  destinations.Publisher
+	results    *resultBatcher
+	workerID   string
+	heartbeat  *health.Heartbeat
 	lg         logger.Logger
 }
 
 // NewOutBoxWorker initializes and returns the OutboxWorker
 func NewOutBoxWorker() *OutboxWorker {
 	dp := GetAppDependency()
-	// Initialize the worker pool with a defined concurrency limit
-	workerPool := make(chan bool, dp.cfg.Outbox.MaxConcurrency) // Limit concurrency to 10 workers
+	// Initialize the worker pool with a defined concurrency limit, a config reload resizes it
+	workerPool := newWorkerPool(dp.cfg.Outbox.MaxConcurrency)
+	configs.OnReload(func(c *configs.AppConfig) {
+		workerPool.Resize(c.Outbox.MaxConcurrency)
+	})
+
+	// the relay has no Kafka or RabbitMQ client yet, destinations.Kafka and
+	// destinations.AMQP only wrap a client interface. Their rows keep being marked
+	// sent without being published, as they always were.
+	router := destinations.NewRouter()
+	router.Register(models.OutboxDestinationTypeAsynq, destinations.NewAsynq(dp.queue, dp.cfg.AsyncQ.MaxRetries, dp.cfg.Outbox.AsynqEnqueueConcurrency))
+	router.Register(models.OutboxDestinationTypeKafka, destinations.Nop{})
+	router.Register(models.OutboxDestinationTypeRabbitmq, destinations.Nop{})
+	dp.log.Warn("No Kafka or RabbitMQ client is configured, outbox rows for them are marked sent without being published")
+	publisher := destinations.NewGuard(router, guardConfig(dp.cfg.Outbox))
+
+	// results are written back in batches instead of a transaction per message,
+	// together with the attempt history
+	outboxDomain := outbox.NewOutboxDomain(dp.cfg, dp.log, dp.db)
+	uow := databases.NewUnitOfWork(dp.db)
+	results := newResultBatcher(
+		func(ctx context.Context, results []models.OutboxResult) error {
+			var attempts []models.OutboxAttempt
+			for _, result := range results {
+				if result.Attempt != nil {
+					attempts = append(attempts, *result.Attempt)
+				}
+			}
+
+			return uow.Do(ctx, func(tx databases.Tx) error {
+				if err := outboxDomain.UpdateOutboxResults(ctx, results, tx.DbOptions()); err != nil {
+					return err
+				}
+				return outboxDomain.CreateOutboxAttempts(ctx, attempts, tx.DbOptions())
+			})
+		},
+		dp.cfg.Outbox.ResultFlushSize,
+		time.Duration(dp.cfg.Outbox.ResultFlushIntervalInMs)*time.Millisecond,
+		dp.log,
+	)
 
 	return &OutboxWorker{
 		db:         dp.db,
 		cfg:        dp.cfg,
 		queue:      dp.queue,
 		workerPool: workerPool,
+		publisher:  publisher,
+		results:    results,
+		workerID:   outboxWorkerID(dp.cfg.Outbox.WorkerID),
+		heartbeat:  &health.Heartbeat{},
 		lg:         dp.log,
 	}
 }
 
-// ProcessOutboxJobs processes jobs with limited concurrency
+// outboxWorkerID is the configured ID of the relay instance, host name and pid when empty
+func outboxWorkerID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+func guardConfig(c configs.Outbox) destinations.GuardConfig {
+	cfg := destinations.GuardConfig{
+		FailureThreshold: c.CircuitBreakerFailureThreshold,
+		OpenTimeout:      time.Duration(c.CircuitBreakerOpenTimeoutInSeconds) * time.Second,
+	}
+	for _, limit := range c.RateLimits {
+		cfg.RateLimits = append(cfg.RateLimits, destinations.RateLimit{
+			Destination: limit.Destination,
+			EventType:   limit.EventType,
+			PerSecond:   limit.PerSecond,
+			Burst:       limit.Burst,
+		})
+	}
+	return cfg
+}
+
+// Run fetches and publishes outbox rows until ctx is done. It only fetches as
+// many rows as there are free worker slots, fetches again right away after a
+// full batch and backs off while the table is empty or the database fails.
 func (o *OutboxWorker) Run(ctx context.Context, wg *sync.WaitGroup) {
+	poll := &pollScheduler{}
+
 	for {
-		select {
-		case <-ctx.Done():
+		o.heartbeat.Beat()
+
+		// batch size, intervals and retries are live settings, read them once per iteration
+		outboxCfg := configs.Current().Outbox
+		poll.base = time.Duration(outboxCfg.DurationIntervalInMs) * time.Millisecond
+		poll.max = time.Duration(outboxCfg.MaxIdleIntervalInMs) * time.Millisecond
+
+		// the slots are taken before the fetch, a fetched row always has one
+		limit, err := o.workerPool.Acquire(ctx, outboxCfg.MaxBatchSize)
+		if err != nil {
+			o.lg.InfoWithContext(ctx, "Shutting down outbox worker...")
+			return
+		}
+
+		fetched, err := o.processOutboxJobs(ctx, wg, limit, outboxCfg.PublishBatchSize, outboxCfg.MaxRetries)
+
+		if !sleepContext(ctx, jitter(poll.Next(fetched, limit, err))) {
 			o.lg.InfoWithContext(ctx, "Shutting down outbox worker...")
-			close(o.workerPool)
 			return
-		default:
-			o.processOutboxJobs(ctx, wg)
 		}
 	}
 }
 
-func (o *OutboxWorker) processOutboxJobs(ctx context.Context, wg *sync.WaitGroup) error {
-	tx := o.db.Begin()
-
-	delayNextIteration := time.Duration(rand.Intn(o.cfg.Outbox.DurationIntervalInMs)) * time.Millisecond
+// processOutboxJobs marks up to limit rows PROCESSING and publishes them with
+// the limit worker slots the caller acquired, the slots no row needs are released
+// right away. It returns how many rows it fetched.
+func (o *OutboxWorker) processOutboxJobs(ctx context.Context, wg *sync.WaitGroup, limit, publishBatchSize, maxRetries int) (int, error) {
+	fetched := 0
+	defer func() {
+		o.workerPool.ReleaseN(limit - fetched)
+	}()
 
+	tx := o.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
-		o.lg.ErrorWithContext(ctx, "Error starting transaction: %v", tx.Error)
-		time.Sleep(delayNextIteration)
-		return tx.Error
+		o.lg.ErrorWithContext(ctx, fmt.Sprintf("Error starting transaction: %v", tx.Error))
+		return 0, tx.Error
 	}
 
-	// Fetch 100 messages to process using raw SQL
+	// Fetch the messages to process using raw SQL. A row with an ordering key is
+	// only picked once every older row with the same key has been sent or failed.
 	var outboxes []models.Outbox
 	err := tx.Raw(`
-			SELECT id, status, attempt, execute_at, destination_type, event_type, payload
+			SELECT id, status, attempt, execute_at, destination_type, event_type, payload, topic, ordering_key
 			FROM outbox
 			WHERE status IN (?, ?) AND execute_at <= ?
+			AND (ordering_key IS NULL OR NOT EXISTS (
+				SELECT 1 FROM outbox prev
+				WHERE prev.ordering_key = outbox.ordering_key
+				AND prev.status IN (?, ?, ?)
+				AND prev.created_at < outbox.created_at
+			))
 			ORDER BY execute_at asc
 			FOR UPDATE SKIP LOCKED
 			LIMIT ?
-		`, models.OutboxStatusPending, models.OutboxStatusRetrying, time.Now(), o.cfg.Outbox.MaxBatchSize).Scan(&outboxes).Error
+		`, models.OutboxStatusPending, models.OutboxStatusRetrying, time.Now(),
+		models.OutboxStatusPending, models.OutboxStatusRetrying, models.OutboxStatusProcessing,
+		limit).Scan(&outboxes).Error
 
 	if err != nil {
 		tx.Rollback()
-		o.lg.ErrorWithContext(ctx, "Error fetching data from outbox: %v", err)
-		time.Sleep(delayNextIteration)
-		return err
+		o.lg.ErrorWithContext(ctx, fmt.Sprintf("Error fetching data from outbox: %v", err))
+		return 0, err
 	}
 
 	if len(outboxes) == 0 {
-		o.lg.InfoWithContext(ctx, "No outboxes to process")
 		tx.Rollback()
-		// Sleep for the jitter time
-		o.lg.InfoWithContext(ctx, "Sleeping for %v...\n", delayNextIteration)
-		time.Sleep(delayNextIteration)
-		return nil
+		return 0, nil
 	}
 
-	o.lg.InfoWithContext(ctx, "Found %d outboxes to process", len(outboxes))
+	o.lg.InfoWithContext(ctx, fmt.Sprintf("Found %d outboxes to process", len(outboxes)))
 
 	// Update all selected outboxes to PROCESSING status in the same transaction
 	err = o.setStatusProcessing(ctx, tx, outboxes)
 	if err != nil {
 		tx.Rollback()
-		o.lg.ErrorWithContext(ctx, "Error updating outboxes to PROCESSING: %v", err)
-		time.Sleep(10 * time.Second)
-		return err
+		o.lg.ErrorWithContext(ctx, fmt.Sprintf("Error updating outboxes to PROCESSING: %v", err))
+		return 0, err
 	}
 
 	// Commit the transaction before processing
 	err = tx.Commit().Error
 	if err != nil {
-		o.lg.ErrorWithContext(ctx, "Error committing transaction: %v", err)
-		time.Sleep(delayNextIteration)
-		return err
+		o.lg.ErrorWithContext(ctx, fmt.Sprintf("Error committing transaction: %v", err))
+		return 0, err
 	}
+	fetched = len(outboxes)
+
+	// Publish the outboxes in batches, each batch holds a worker slot per message
+	for len(outboxes) > 0 {
+		n := min(len(outboxes), publishBatchSize)
+		batch := outboxes[:n]
+		outboxes = outboxes[n:]
 
-	// Process the outboxes concurrently with a worker pool
-	for _, outbox := range outboxes {
 		wg.Add(1)
-		o.workerPool <- true // Acquire a worker slot
-		go func(outbox models.Outbox) {
+		go func(batch []models.Outbox) {
 			defer func() {
 				wg.Done()
-				<-o.workerPool
-			}() // Release worker slot after processing
+				o.workerPool.ReleaseN(len(batch))
+			}() // Release worker slots after publishing
 
-			bgCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			bgCtx, cancel := context.WithTimeout(context.Background(), outboxPublishTimeout)
 			defer cancel()
 
-			o.processMessage(bgCtx, outbox)
-		}(outbox)
+			o.processBatch(bgCtx, batch, maxRetries)
+		}(batch)
 	}
 
-	o.lg.InfoWithContext(ctx, "Sleeping for %v...\n", delayNextIteration)
-	time.Sleep(delayNextIteration)
+	return fetched, nil
+}
 
-	return nil
+// workerPool limits how many messages are processed at once. Unlike a buffered
+// channel its size can change while slots are taken, shrinking it lets the
+// running messages finish and holds new ones back until the pool fits again.
+type workerPool struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	size  int
+	inUse int
 }
 
-// processMessage processes the message, retries on failure, and updates its status
-func (o *OutboxWorker) processMessage(ctx context.Context, outbox models.Outbox) {
-	var err error
+func newWorkerPool(size int) *workerPool {
+	p := &workerPool{size: size}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
 
-	tx := o.db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		o.lg.ErrorWithContext(ctx, "Error starting transaction: %v", tx.Error)
-		return
+// Acquire waits until at least one slot is free and takes the free slots, at
+// most limit of them, it returns how many it took. It returns the error of ctx
+// once ctx is done.
+func (p *workerPool) Acquire(ctx context.Context, limit int) (int, error) {
+	// wake the wait below when ctx is done
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.cond.Broadcast()
+	})
+	defer stop()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.inUse >= p.size && ctx.Err() == nil {
+		p.cond.Wait()
 	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	n := min(p.size-p.inUse, limit)
+	p.inUse += n
+	return n, nil
+}
 
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			o.lg.ErrorWithContext(ctx, "Error processing message %s: %v", outbox.ID, r)
-			return
-		}
-		if err != nil {
-			tx.Rollback()
-			o.lg.ErrorWithContext(ctx, "Error processing message %s: %v", outbox.ID, err)
-			return
-		}
-		err = tx.Commit().Error
-		if err != nil {
-			o.lg.ErrorWithContext(ctx, "Error committing transaction: %v", err)
-		}
-	}()
-	// Simulate message processing, can be replaced with actual processing logic (e.g., sending to a queue)
-	errProcess := o.process(ctx, outbox)
+func (p *workerPool) ReleaseN(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inUse -= n
+	p.cond.Broadcast()
+}
+
+func (p *workerPool) Resize(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.size = size
+	p.cond.Broadcast()
+}
+
+// processBatch publishes the batch and hands the new status and the attempt of
+// every message to the result batcher
+func (o *OutboxWorker) processBatch(ctx context.Context, batch []models.Outbox, maxRetries int) {
+	startedAt := time.Now()
+	published := o.publisher.PublishBatch(ctx, batch)
 	finishedProcessTime := time.Now()
 
-	if errProcess != nil {
-		outbox.ErrorMessage = util.ToPointer(errProcess.Error())
-		// If processing fails, update status to RETRYING
-		if outbox.Attempt >= int64(o.cfg.Outbox.MaxRetries) {
-			// If max retries are reached, update status to FAILED
-			o.lg.ErrorWithContext(ctx, "Reached max retries for processing message %s: %v", outbox.ID, errProcess)
-			outbox.Status = models.OutboxStatusFailed
-
-			err = tx.Updates(outbox).Error
-			if err != nil {
-				o.lg.ErrorWithContext(ctx, "Error updating status for message %s: %v", outbox.ID, err)
-				return
-			}
-			return
-		}
-		// Compute the next retry time using Exponential Backoff
-		expBackoff := backoff.NewExponentialBackOff()
-		expBackoff.InitialInterval = 1 * time.Minute // Start with 1 min delay
-		expBackoff.MaxInterval = 3 * time.Minute     // Max delay between retries
-		expBackoff.MaxElapsedTime = 1 * time.Hour    // Stop retrying after 1 hour
-		expBackoff.Multiplier = 2.0                  // Exponential growth
-		expBackoff.RandomizationFactor = 0.5         // Add jitter to prevent sync issues
-		expBackoff.Reset()                           // Reset to ensure a fresh calculation
-
-		// Calculate new backoff time based on attempt count
-		for i := 0; i < int(outbox.Attempt+1); i++ {
-			expBackoff.NextBackOff() // Advance to correct attempt time
+	for i, outbox := range batch {
+		errProcess := published[i].Err
+
+		var deferred *destinations.DeferredError
+		switch {
+		case goErrors.As(errProcess, &deferred):
+			o.lg.WarnWithContext(ctx, fmt.Sprintf("Deferred message %s: %v", outbox.ID, errProcess))
+		case errProcess != nil:
+			o.lg.ErrorWithContext(ctx, fmt.Sprintf("Error processing message %s: %v", outbox.ID, errProcess))
 		}
-		nextExecuteAt := time.Now().Add(expBackoff.NextBackOff())
 
-		outbox.ExecuteAt = nextExecuteAt
-		outbox.Status = models.OutboxStatusRetrying
+		result := o.result(ctx, outbox, errProcess, finishedProcessTime, maxRetries)
+		result.Attempt = o.attempt(outbox, published[i], result, startedAt, finishedProcessTime)
+		o.results.Add(result)
+	}
+}
 
-		err = tx.Updates(outbox).Error
+// attempt describes the try to publish outbox for the attempt history, it is
+// nil for a row that was deferred before reaching its destination
+func (o *OutboxWorker) attempt(outbox models.Outbox, published destinations.Result, result models.OutboxResult, startedAt, finishedAt time.Time) *models.OutboxAttempt {
+	status := result.Status
 
-		if err != nil {
-			o.lg.ErrorWithContext(ctx, "Error updating status for message %s: %v", outbox.ID, err)
+	var deferred *destinations.DeferredError
+	if goErrors.As(published.Err, &deferred) {
+		if deferred.Err == nil {
+			return nil
 		}
-		return
+		status = models.OutboxAttemptStatusDeferred
 	}
 
-	// Successfully processed, update status to SENT
-	outbox.Status = models.OutboxStatusSent
-	outbox.SentAt = &finishedProcessTime
-	err = tx.Updates(outbox).Error
-	if err != nil {
-		o.lg.ErrorWithContext(ctx, "Error updating status for message %s: %v", outbox.ID, err)
-		return
+	attempt := &models.OutboxAttempt{
+		OutboxID:        outbox.ID,
+		Attempt:         outbox.Attempt,
+		EventType:       outbox.EventType,
+		DestinationType: outbox.DestinationType,
+		Status:          status,
+		WorkerID:        o.workerID,
+		StartedAt:       startedAt,
+		FinishedAt:      finishedAt,
 	}
-
+	if published.Response != "" {
+		attempt.Response = util.ToPointer(published.Response)
+	}
+	if published.Err != nil {
+		attempt.ErrorMessage = util.ToPointer(published.Err.Error())
+	}
+	if result.NextExecuteAt != nil {
+		attempt.BackoffMs = util.ToPointer(result.NextExecuteAt.Sub(finishedAt).Milliseconds())
+	}
+	return attempt
 }
 
-// process processes the message based on the destination type
-func (o *OutboxWorker) process(ctx context.Context, outbox models.Outbox) error {
+// result is SENT on success, RETRYING with a backoff on failure and FAILED on a
+// permanent error or once maxRetries attempts are used up
+func (o *OutboxWorker) result(ctx context.Context, outbox models.Outbox, errProcess error, finishedProcessTime time.Time, maxRetries int) models.OutboxResult {
+	result := models.OutboxResult{
+		ID:        outbox.ID,
+		ExecuteAt: outbox.ExecuteAt,
+	}
+	if outbox.ProcessingStartedAt != nil {
+		result.ProcessingStartedAt = *outbox.ProcessingStartedAt
+	}
 
-	switch outbox.DestinationType {
-	case models.OutboxDestinationTypeAsynq:
-		_, err := o.queue.EnqueueContext(ctx, asynq.NewTask(outbox.EventType, outbox.Payload.Bytes), asynq.MaxRetry(o.cfg.AsyncQ.MaxRetries))
-		return err
-	case models.OutboxDestinationTypeKafka:
-		return nil
-	case models.OutboxDestinationTypeRabbitmq:
-		return nil
-	default:
-		return fmt.Errorf("unsupported destination type: %s", outbox.DestinationType)
+	if errProcess == nil {
+		result.Status = models.OutboxStatusSent
+		result.SentAt = &finishedProcessTime
+		return result
+	}
+
+	// A deferred row was held back by a circuit breaker or rate limit, it
+	// keeps its status and gets its attempt back
+	var deferred *destinations.DeferredError
+	if goErrors.As(errProcess, &deferred) {
+		result.Status = outbox.Status
+		result.NextExecuteAt = &deferred.Until
+		result.RefundAttempt = true
+		return result
 	}
+
+	result.ErrorMessage = util.ToPointer(errProcess.Error())
+
+	if destinations.IsPermanent(errProcess) {
+		// A permanent error fails the same way on every attempt, dead-letter it now
+		o.lg.ErrorWithContext(ctx, fmt.Sprintf("Permanent error processing message %s: %v", outbox.ID, errProcess))
+		result.Status = models.OutboxStatusFailed
+		return result
+	}
+
+	if outbox.Attempt >= int64(maxRetries) {
+		// If max retries are reached, update status to FAILED
+		o.lg.ErrorWithContext(ctx, fmt.Sprintf("Reached max retries for processing message %s: %v", outbox.ID, errProcess))
+		result.Status = models.OutboxStatusFailed
+		return result
+	}
+
+	// Compute the next retry time using Exponential Backoff
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.InitialInterval = 1 * time.Minute // Start with 1 min delay
+	expBackoff.MaxInterval = 3 * time.Minute     // Max delay between retries
+	expBackoff.MaxElapsedTime = 1 * time.Hour    // Stop retrying after 1 hour
+	expBackoff.Multiplier = 2.0                  // Exponential growth
+	expBackoff.RandomizationFactor = 0.5         // Add jitter to prevent sync issues
+	expBackoff.Reset()                           // Reset to ensure a fresh calculation
+
+	// Calculate new backoff time based on attempt count
+	for i := 0; i < int(outbox.Attempt+1); i++ {
+		expBackoff.NextBackOff() // Advance to correct attempt time
+	}
+	nextExecuteAt := time.Now().Add(expBackoff.NextBackOff())
+
+	// the wait the destination asked for wins over the computed backoff
+	if retryAfter, ok := destinations.RetryAfter(errProcess); ok {
+		nextExecuteAt = time.Now().Add(retryAfter)
+	}
+
+	result.Status = models.OutboxStatusRetrying
+	result.NextExecuteAt = &nextExecuteAt
+	return result
 }
 
+// setStatusProcessing takes a lease on the rows, their results are only written
+// back while they hold it and the reaper requeues them once it expired
 func (o *OutboxWorker) setStatusProcessing(ctx context.Context, tx *gorm.DB, outboxes []models.Outbox) error {
+	// Postgres keeps microseconds, the lease is compared with the stored value
+	startedAt := time.Now().Truncate(time.Microsecond)
+
 	outboxIds := make([]strfmt.UUID4, len(outboxes))
 	for i, outbox := range outboxes {
 		outboxIds[i] = outbox.ID
+		outboxes[i].ProcessingStartedAt = &startedAt
 	}
 
-	qUpdate := "UPDATE outbox SET status = ?, attempt = attempt + 1 WHERE id IN ?"
-	err := tx.Exec(qUpdate, models.OutboxStatusProcessing, outboxIds).Error
+	qUpdate := "UPDATE outbox SET status = ?, attempt = attempt + 1, processing_started_at = ? WHERE id IN ?"
+	err := tx.Exec(qUpdate, models.OutboxStatusProcessing, startedAt, outboxIds).Error
 
 	if err != nil {
 		o.lg.ErrorWithContext(ctx, "error set status processing for outbox_ids: %v err: %v", outboxIds, err)
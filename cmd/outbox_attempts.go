@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"eventdrivensystem/internal/domain/outbox"
+	"eventdrivensystem/pkg/logger"
+	"eventdrivensystem/pkg/util"
+	"fmt"
+	"time"
+)
+
+const (
+	attemptPruneInterval  = time.Hour
+	attemptPruneBatchSize = 10000
+)
+
+// startOutboxAttemptPruner adds to lc a loop that deletes the attempt history
+// older than Outbox.AttemptRetentionInHours once an hour
+func startOutboxAttemptPruner(lc *Lifecycle) {
+	dp := GetAppDependency()
+	if dp.cfg.Outbox.AttemptRetentionInHours == 0 {
+		return
+	}
+
+	retention := time.Duration(dp.cfg.Outbox.AttemptRetentionInHours) * time.Hour
+	outboxDomain := outbox.NewOutboxDomain(dp.cfg, dp.log, dp.db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(Hook{
+		Name: "outbox-attempts-pruner",
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				for {
+					pruneOutboxAttempts(ctx, outboxDomain, time.Now().Add(-retention), dp.log)
+					if !sleepContext(ctx, attemptPruneInterval) {
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+type outboxAttemptDeleter interface {
+	DeleteOutboxAttemptsBefore(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error)
+}
+
+// pruneOutboxAttempts deletes the attempts that finished before before, a chunk at a time
+func pruneOutboxAttempts(ctx context.Context, d outboxAttemptDeleter, before time.Time, lg logger.Logger) int64 {
+	var total int64
+	for {
+		n, err := d.DeleteOutboxAttemptsBefore(ctx, before, attemptPruneBatchSize)
+		total += n
+		if err != nil {
+			if ctx.Err() == nil {
+				lg.ErrorWithContext(ctx, fmt.Sprintf("Error pruning outbox attempts: %v", err))
+			}
+			return total
+		}
+		if n < attemptPruneBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		lg.InfoWithContext(ctx, fmt.Sprintf("Pruned %d outbox attempts finished before %s", total, before.Format(time.RFC3339)))
+	}
+	return total
+}
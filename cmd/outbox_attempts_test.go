@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	goErrors "errors"
+	"eventdrivensystem/pkg/util"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+type fakeAttemptDeleter struct {
+	deleted []int64
+	err     error
+	calls   int
+}
+
+func (f *fakeAttemptDeleter) DeleteOutboxAttemptsBefore(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error) {
+	f.calls++
+	if len(f.deleted) == 0 {
+		return 0, f.err
+	}
+	n := f.deleted[0]
+	f.deleted = f.deleted[1:]
+	return n, nil
+}
+
+func TestPruneOutboxAttemptsDeletesInChunks(t *testing.T) {
+	d := &fakeAttemptDeleter{deleted: []int64{attemptPruneBatchSize, attemptPruneBatchSize, 3}}
+
+	total := pruneOutboxAttempts(context.Background(), d, time.Now(), discardLogger())
+	assert.Equal(t, total, int64(2*attemptPruneBatchSize+3))
+	assert.Equal(t, d.calls, 3)
+}
+
+func TestPruneOutboxAttemptsStopsOnError(t *testing.T) {
+	d := &fakeAttemptDeleter{deleted: []int64{attemptPruneBatchSize}, err: goErrors.New("statement timeout")}
+
+	total := pruneOutboxAttempts(context.Background(), d, time.Now(), discardLogger())
+	assert.Equal(t, total, int64(attemptPruneBatchSize))
+	assert.Equal(t, d.calls, 2)
+}
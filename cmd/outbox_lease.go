@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"eventdrivensystem/internal/domain/outbox"
+	"eventdrivensystem/pkg/logger"
+	"eventdrivensystem/pkg/util"
+	"fmt"
+	"time"
+)
+
+const (
+	outboxReapInterval  = time.Minute
+	outboxReapBatchSize = 1000
+)
+
+// startOutboxReaper adds to lc a loop that requeues the rows whose processing
+// lease of Outbox.ProcessingLeaseInSeconds expired, once a minute. A lost result
+// would otherwise keep the row PROCESSING and block every later row of its ordering key.
+func startOutboxReaper(lc *Lifecycle) {
+	dp := GetAppDependency()
+
+	lease := time.Duration(dp.cfg.Outbox.ProcessingLeaseInSeconds) * time.Second
+	minLease := minProcessingLease(time.Duration(dp.cfg.Outbox.ResultFlushIntervalInMs) * time.Millisecond)
+	outboxDomain := outbox.NewOutboxDomain(dp.cfg, dp.log, dp.db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(Hook{
+		Name: "outbox-reaper",
+		OnStart: func(context.Context) error {
+			if lease < minLease {
+				return fmt.Errorf("processing lease of %s is too short, Outbox.ProcessingLeaseInSeconds must cover at least %s", lease, minLease)
+			}
+
+			go func() {
+				defer close(done)
+				for {
+					requeueStaleOutboxes(ctx, outboxDomain, time.Now().Add(-lease), dp.log)
+					if !sleepContext(ctx, jitter(outboxReapInterval)) {
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+// minProcessingLease is the longest a row can stay PROCESSING while its relay is
+// healthy: the publish, a wait of up to flushInterval and the flush of the batch
+// ahead of its result, then the flush of its own result with every retry. A
+// shorter lease requeues rows that are still being published.
+func minProcessingLease(flushInterval time.Duration) time.Duration {
+	return outboxPublishTimeout + flushInterval + 2*resultFlushBudget()
+}
+
+type outboxRequeuer interface {
+	RequeueStaleOutboxes(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error)
+}
+
+// requeueStaleOutboxes moves the rows PROCESSING since before back to RETRYING, a chunk at a time
+func requeueStaleOutboxes(ctx context.Context, d outboxRequeuer, before time.Time, lg logger.Logger) int64 {
+	var total int64
+	for {
+		n, err := d.RequeueStaleOutboxes(ctx, before, outboxReapBatchSize)
+		total += n
+		if err != nil {
+			if ctx.Err() == nil {
+				lg.ErrorWithContext(ctx, fmt.Sprintf("Error requeueing stale outboxes: %v", err))
+			}
+			return total
+		}
+		if n < outboxReapBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		lg.WarnWithContext(ctx, fmt.Sprintf("Requeued %d outboxes PROCESSING since before %s", total, before.Format(time.RFC3339)))
+	}
+	return total
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"context"
+	goErrors "errors"
+	"eventdrivensystem/pkg/util"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+type fakeOutboxRequeuer struct {
+	requeued []int64
+	err      error
+	calls    int
+}
+
+func (f *fakeOutboxRequeuer) RequeueStaleOutboxes(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error) {
+	f.calls++
+	if len(f.requeued) == 0 {
+		return 0, f.err
+	}
+	n := f.requeued[0]
+	f.requeued = f.requeued[1:]
+	return n, nil
+}
+
+func TestRequeueStaleOutboxesInChunks(t *testing.T) {
+	d := &fakeOutboxRequeuer{requeued: []int64{outboxReapBatchSize, 7}}
+
+	total := requeueStaleOutboxes(context.Background(), d, time.Now(), discardLogger())
+	assert.Equal(t, total, int64(outboxReapBatchSize+7))
+	assert.Equal(t, d.calls, 2)
+}
+
+func TestRequeueStaleOutboxesStopsOnError(t *testing.T) {
+	d := &fakeOutboxRequeuer{err: goErrors.New("connection refused")}
+
+	total := requeueStaleOutboxes(context.Background(), d, time.Now(), discardLogger())
+	assert.Equal(t, total, int64(0))
+	assert.Equal(t, d.calls, 1)
+}
+
+func TestMinProcessingLease(t *testing.T) {
+	// 30s publish, the 200ms default flush interval and two flushes of 3 tries of 30s with their backoff
+	lease := minProcessingLease(200 * time.Millisecond)
+	assert.Equal(t, lease, 211400*time.Millisecond)
+
+	// stays below the min=300 of Outbox.ProcessingLeaseInSeconds
+	assert.Assert(t, lease < 300*time.Second)
+}
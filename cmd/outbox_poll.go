@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// pollScheduler picks the delay before the next fetch of the relay. A full
+// batch means more rows are waiting, so the next fetch runs right away. Empty
+// fetches and errors double the delay from base up to max, a partial batch
+// resets it to base.
+type pollScheduler struct {
+	base time.Duration
+	max  time.Duration
+
+	empty  int
+	failed int
+}
+
+// Next returns the delay after a fetch that returned fetched of limit rows
+func (p *pollScheduler) Next(fetched, limit int, err error) time.Duration {
+	switch {
+	case err != nil:
+		p.empty = 0
+		p.failed++
+		return p.backoff(p.failed)
+	case fetched == 0:
+		p.failed = 0
+		p.empty++
+		return p.backoff(p.empty)
+	case fetched >= limit:
+		p.empty, p.failed = 0, 0
+		return 0
+	default:
+		p.empty, p.failed = 0, 0
+		return p.base
+	}
+}
+
+// backoff is base doubled for every consecutive empty fetch or error after the first
+func (p *pollScheduler) backoff(streak int) time.Duration {
+	ceiling := max(p.max, p.base)
+	d := p.base
+	for i := 1; i < streak && d < ceiling; i++ {
+		d *= 2
+	}
+	return min(d, ceiling)
+}
+
+// jitter spreads d over [d/2, d) so that relay instances don't poll in step
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)))
+}
+
+// sleepContext waits for d and reports false when ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	goErrors "errors"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestPollSchedulerAdaptsToFetches(t *testing.T) {
+	p := &pollScheduler{base: time.Second, max: 5 * time.Second}
+
+	assert.Equal(t, p.Next(100, 100, nil), time.Duration(0))
+	assert.Equal(t, p.Next(40, 100, nil), time.Second)
+
+	assert.Equal(t, p.Next(0, 100, nil), time.Second)
+	assert.Equal(t, p.Next(0, 100, nil), 2*time.Second)
+	assert.Equal(t, p.Next(0, 100, nil), 4*time.Second)
+	assert.Equal(t, p.Next(0, 100, nil), 5*time.Second)
+
+	assert.Equal(t, p.Next(100, 100, nil), time.Duration(0))
+	assert.Equal(t, p.Next(0, 100, nil), time.Second)
+
+	failed := goErrors.New("connection refused")
+	assert.Equal(t, p.Next(0, 100, failed), time.Second)
+	assert.Equal(t, p.Next(0, 100, failed), 2*time.Second)
+	assert.Equal(t, p.Next(0, 100, nil), time.Second)
+}
+
+func TestSleepContextReturnsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	assert.Assert(t, !sleepContext(ctx, time.Hour))
+	assert.Assert(t, time.Since(start) < time.Second)
+	assert.Assert(t, !sleepContext(ctx, 0))
+}
+
+func TestWorkerPoolAcquire(t *testing.T) {
+	p := newWorkerPool(3)
+
+	n, err := p.Acquire(context.Background(), 2)
+	assert.NilError(t, err)
+	assert.Equal(t, n, 2)
+
+	n, err = p.Acquire(context.Background(), 5)
+	assert.NilError(t, err)
+	assert.Equal(t, n, 1)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		p.ReleaseN(2)
+	}()
+
+	n, err = p.Acquire(context.Background(), 5)
+	assert.NilError(t, err)
+	assert.Equal(t, n, 2)
+
+	// a shrunk pool holds new slots back until the running ones fit again
+	p.Resize(2)
+	p.ReleaseN(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = p.Acquire(ctx, 1)
+	assert.Equal(t, err, context.DeadlineExceeded)
+}
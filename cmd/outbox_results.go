@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/logger"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultResultFlushRetries = 3
+	resultFlushTimeout        = 30 * time.Second
+	resultFlushBackoff        = 100 * time.Millisecond
+)
+
+// resultFlushBudget is the longest flush takes before it gives up on a batch
+func resultFlushBudget() time.Duration {
+	budget := defaultResultFlushRetries * resultFlushTimeout
+	for attempt := 1; attempt <= defaultResultFlushRetries; attempt++ {
+		budget += time.Duration(attempt) * resultFlushBackoff
+	}
+	return budget
+}
+
+// resultBatcher collects relay results and writes them back in batches, it
+// flushes when flushSize results are waiting or flushInterval passed since the
+// first of them. Add blocks while the buffer is full, which slows the workers
+// down when the database can't keep up.
+type resultBatcher struct {
+	write         func(ctx context.Context, results []models.OutboxResult) error
+	flushSize     int
+	flushInterval time.Duration
+	lg            logger.Logger
+
+	results chan models.OutboxResult
+	done    chan struct{}
+}
+
+func newResultBatcher(write func(ctx context.Context, results []models.OutboxResult) error, flushSize int, flushInterval time.Duration, lg logger.Logger) *resultBatcher {
+	b := &resultBatcher{
+		write:         write,
+		flushSize:     flushSize,
+		flushInterval: flushInterval,
+		lg:            lg,
+		results:       make(chan models.OutboxResult, flushSize),
+		done:          make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (b *resultBatcher) Add(result models.OutboxResult) {
+	b.results <- result
+}
+
+// Close flushes what is left and returns once it is written, Add must not be called afterwards
+func (b *resultBatcher) Close() {
+	close(b.results)
+	<-b.done
+}
+
+func (b *resultBatcher) run() {
+	defer close(b.done)
+
+	batch := make([]models.OutboxResult, 0, b.flushSize)
+	timer := time.NewTimer(b.flushInterval)
+	timer.Stop()
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		b.flush(batch)
+		batch = make([]models.OutboxResult, 0, b.flushSize)
+	}
+
+	for {
+		select {
+		case result, ok := <-b.results:
+			if !ok {
+				timer.Stop()
+				flush()
+				return
+			}
+
+			if len(batch) == 0 {
+				timer.Reset(b.flushInterval)
+			}
+			batch = append(batch, result)
+
+			if len(batch) >= b.flushSize {
+				timer.Stop()
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// flush retries a failed write. Rows whose result is lost stay PROCESSING until
+// their lease expires and the reaper requeues them, see startOutboxReaper.
+func (b *resultBatcher) flush(batch []models.OutboxResult) {
+	var err error
+	for attempt := 1; attempt <= defaultResultFlushRetries; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), resultFlushTimeout)
+		err = b.write(ctx, batch)
+		cancel()
+		if err == nil {
+			return
+		}
+		time.Sleep(time.Duration(attempt) * resultFlushBackoff)
+	}
+
+	b.lg.Error(fmt.Sprintf("Error writing %d outbox results: %v", len(batch), err))
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	goErrors "errors"
+	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/domain/outbox"
+	models "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/logger"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"gotest.tools/assert"
+)
+
+type recordedWrites struct {
+	mu      sync.Mutex
+	batches [][]models.OutboxResult
+	failing int
+}
+
+func (r *recordedWrites) write(ctx context.Context, results []models.OutboxResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failing > 0 {
+		r.failing--
+		return goErrors.New("connection reset")
+	}
+	r.batches = append(r.batches, results)
+	return nil
+}
+
+func (r *recordedWrites) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sizes []int
+	for _, batch := range r.batches {
+		sizes = append(sizes, len(batch))
+	}
+	return sizes
+}
+
+func discardLogger() logger.Logger {
+	return logger.Init(logger.Options{Output: logger.OutputDiscard})
+}
+
+func TestResultBatcherFlushesOnSize(t *testing.T) {
+	writes := &recordedWrites{}
+	b := newResultBatcher(writes.write, 2, time.Hour, discardLogger())
+
+	for i := 0; i < 5; i++ {
+		b.Add(models.OutboxResult{Status: models.OutboxStatusSent})
+	}
+	b.Close()
+
+	assert.DeepEqual(t, writes.sizes(), []int{2, 2, 1})
+}
+
+func TestResultBatcherFlushesOnInterval(t *testing.T) {
+	writes := &recordedWrites{}
+	b := newResultBatcher(writes.write, 100, 10*time.Millisecond, discardLogger())
+	defer b.Close()
+
+	b.Add(models.OutboxResult{Status: models.OutboxStatusSent})
+
+	deadline := time.Now().Add(time.Second)
+	for len(writes.sizes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.DeepEqual(t, writes.sizes(), []int{1})
+}
+
+func TestResultBatcherRetriesFailedWrite(t *testing.T) {
+	writes := &recordedWrites{failing: 2}
+	b := newResultBatcher(writes.write, 1, time.Hour, discardLogger())
+
+	b.Add(models.OutboxResult{Status: models.OutboxStatusSent})
+	b.Close()
+
+	assert.DeepEqual(t, writes.sizes(), []int{1})
+}
+
+func TestResultBatcherDropsBatchAfterLastFailedWrite(t *testing.T) {
+	writes := &recordedWrites{failing: defaultResultFlushRetries}
+	b := newResultBatcher(writes.write, 1, time.Hour, discardLogger())
+
+	// the lost row is left to the reaper, the next batch is still written
+	b.Add(models.OutboxResult{ID: "lost", Status: models.OutboxStatusSent})
+	b.Add(models.OutboxResult{ID: "written", Status: models.OutboxStatusSent})
+	b.Close()
+
+	assert.DeepEqual(t, writes.sizes(), []int{1})
+	assert.Equal(t, writes.batches[0][0].ID, strfmt.UUID4("written"))
+}
+
+// BenchmarkOutboxStatusUpdates compares writing relay results with a transaction
+// per message against the result batcher. It needs a migrated database:
+//
+//	OUTBOX_BENCH_DSN=postgres://... go test ./cmd -run ^$ -bench OutboxStatusUpdates
+func BenchmarkOutboxStatusUpdates(b *testing.B) {
+	dsn := os.Getenv("OUTBOX_BENCH_DSN")
+	if dsn == "" {
+		b.Skip("OUTBOX_BENCH_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger.Discard})
+	assert.NilError(b, err)
+	sqlDB, err := db.DB()
+	assert.NilError(b, err)
+	sqlDB.SetMaxOpenConns(30)
+	defer sqlDB.Close()
+
+	const concurrency = 30
+
+	b.Run("per-message", func(b *testing.B) {
+		rows := seedBenchOutboxes(b, db, b.N)
+		b.ResetTimer()
+
+		runConcurrently(rows, concurrency, func(row models.Outbox) {
+			sentAt := time.Now()
+			tx := db.Begin()
+			err := tx.Model(&models.Outbox{}).
+				Where("id = ? AND execute_at = ?", row.ID, row.ExecuteAt).
+				Updates(map[string]interface{}{"status": models.OutboxStatusSent, "sent_at": sentAt}).Error
+			if err != nil {
+				tx.Rollback()
+				b.Error(err)
+				return
+			}
+			if err := tx.Commit().Error; err != nil {
+				b.Error(err)
+			}
+		})
+
+		b.StopTimer()
+		b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "rows/s")
+	})
+
+	b.Run("batched", func(b *testing.B) {
+		rows := seedBenchOutboxes(b, db, b.N)
+		domain := outbox.NewOutboxDomain(&configs.AppConfig{}, discardLogger(), db)
+		b.ResetTimer()
+
+		results := newResultBatcher(func(ctx context.Context, results []models.OutboxResult) error {
+			return domain.UpdateOutboxResults(ctx, results)
+		}, 500, 200*time.Millisecond, discardLogger())
+
+		runConcurrently(rows, concurrency, func(row models.Outbox) {
+			sentAt := time.Now()
+			results.Add(models.OutboxResult{ID: row.ID, ExecuteAt: row.ExecuteAt, ProcessingStartedAt: *row.ProcessingStartedAt, Status: models.OutboxStatusSent, SentAt: &sentAt})
+		})
+		results.Close()
+
+		b.StopTimer()
+		b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "rows/s")
+	})
+}
+
+// seedBenchOutboxes inserts n PROCESSING rows that the relay would publish next
+func seedBenchOutboxes(b *testing.B, db *gorm.DB, n int) []models.Outbox {
+	b.Helper()
+
+	executeAt := time.Now().Add(-time.Minute).UTC().Truncate(time.Microsecond)
+	rows := make([]models.Outbox, n)
+	for i := range rows {
+		rows[i] = models.Outbox{
+			ID:                  strfmt.UUID4(uuid.NewString()),
+			Payload:             &pgtype.JSONB{Bytes: []byte(`{}`), Status: pgtype.Present},
+			EventType:           "bench",
+			Status:              models.OutboxStatusProcessing,
+			CreatedAt:           executeAt,
+			Attempt:             1,
+			DestinationType:     models.OutboxDestinationTypeAsynq,
+			ExecuteAt:           executeAt,
+			ProcessingStartedAt: &executeAt,
+		}
+	}
+
+	assert.NilError(b, db.CreateInBatches(rows, 1000).Error)
+	b.Cleanup(func() {
+		db.Where("event_type = ?", "bench").Delete(&models.Outbox{})
+	})
+	return rows
+}
+
+func runConcurrently(rows []models.Outbox, concurrency int, fn func(row models.Outbox)) {
+	work := make(chan models.Outbox)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range work {
+				fn(row)
+			}
+		}()
+	}
+
+	for _, row := range rows {
+		work <- row
+	}
+	close(work)
+	wg.Wait()
+}
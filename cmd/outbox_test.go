@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	goErrors "errors"
+	"eventdrivensystem/internal/destinations"
+	models "eventdrivensystem/internal/models/outbox"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestOutboxResultClassifiesErrors(t *testing.T) {
+	o := &OutboxWorker{
+		lg: discardLogger(),
+	}
+	row := models.Outbox{ID: "1", Status: models.OutboxStatusPending, Attempt: 1, ExecuteAt: time.Now()}
+	failed := goErrors.New("connection refused")
+
+	result := o.result(context.Background(), row, nil, time.Now(), 3)
+	assert.Equal(t, result.Status, models.OutboxStatusSent)
+
+	result = o.result(context.Background(), row, destinations.Permanent(failed), time.Now(), 3)
+	assert.Equal(t, result.Status, models.OutboxStatusFailed)
+
+	result = o.result(context.Background(), row, failed, time.Now(), 3)
+	assert.Equal(t, result.Status, models.OutboxStatusRetrying)
+	assert.Assert(t, time.Until(*result.NextExecuteAt) > 30*time.Second)
+
+	result = o.result(context.Background(), row, destinations.Retryable(failed, 5*time.Second), time.Now(), 3)
+	assert.Equal(t, result.Status, models.OutboxStatusRetrying)
+	assert.Assert(t, time.Until(*result.NextExecuteAt) <= 5*time.Second)
+
+	until := time.Now().Add(time.Minute)
+	result = o.result(context.Background(), row, &destinations.DeferredError{Until: until, Reason: "rate limited"}, time.Now(), 3)
+	assert.Equal(t, result.Status, models.OutboxStatusPending)
+	assert.Equal(t, *result.NextExecuteAt, until)
+	assert.Assert(t, result.RefundAttempt)
+
+	row.Attempt = 3
+	result = o.result(context.Background(), row, failed, time.Now(), 3)
+	assert.Equal(t, result.Status, models.OutboxStatusFailed)
+}
+
+func TestOutboxAttemptRecordsOutcome(t *testing.T) {
+	o := &OutboxWorker{
+		lg:       discardLogger(),
+		workerID: "relay-1",
+	}
+	row := models.Outbox{ID: "1", Status: models.OutboxStatusPending, Attempt: 1, EventType: "email:send_notification", ExecuteAt: time.Now()}
+	startedAt := time.Now()
+	finishedAt := startedAt.Add(20 * time.Millisecond)
+
+	sent := destinations.Result{Response: "task 1 enqueued to default"}
+	attempt := o.attempt(row, sent, o.result(context.Background(), row, nil, finishedAt, 3), startedAt, finishedAt)
+	assert.Equal(t, attempt.Status, models.OutboxStatusSent)
+	assert.Equal(t, attempt.WorkerID, "relay-1")
+	assert.Equal(t, *attempt.Response, "task 1 enqueued to default")
+	assert.Assert(t, attempt.BackoffMs == nil)
+
+	failed := destinations.Result{Err: destinations.Retryable(goErrors.New("timeout"), 10*time.Second)}
+	attempt = o.attempt(row, failed, o.result(context.Background(), row, failed.Err, finishedAt, 3), startedAt, finishedAt)
+	assert.Equal(t, attempt.Status, models.OutboxStatusRetrying)
+	assert.Equal(t, *attempt.ErrorMessage, "timeout (retry after 10s)")
+	assert.Assert(t, *attempt.BackoffMs > 9000)
+
+	until := time.Now().Add(time.Minute)
+	held := destinations.Result{Err: &destinations.DeferredError{Until: until, Reason: "rate limited"}}
+	assert.Assert(t, o.attempt(row, held, o.result(context.Background(), row, held.Err, finishedAt, 3), startedAt, finishedAt) == nil)
+
+	tripped := destinations.Result{Err: &destinations.DeferredError{Until: until, Reason: "circuit breaker of ASYNQ is open", Err: goErrors.New("redis down")}}
+	attempt = o.attempt(row, tripped, o.result(context.Background(), row, tripped.Err, finishedAt, 3), startedAt, finishedAt)
+	assert.Equal(t, attempt.Status, models.OutboxAttemptStatusDeferred)
+}
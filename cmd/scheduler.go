@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"eventdrivensystem/internal/domain"
+	schedulerModels "eventdrivensystem/internal/models/scheduler"
+	"eventdrivensystem/internal/usecase"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Runs the cron scheduler",
+	Run: func(cmd *cobra.Command, args []string) {
+		lc := newAppLifecycle()
+		StartScheduler(lc)
+		if err := lc.Run(); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+// StartScheduler adds the scheduler to lc, stopping it waits for the running tick
+func StartScheduler(lc *Lifecycle) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(Hook{
+		Name: "scheduler",
+		OnStart: func(context.Context) error {
+			go func() {
+				RunScheduler(ctx)
+				close(done)
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-stopCtx.Done():
+				return stopCtx.Err()
+			}
+		},
+	})
+}
+
+// RunScheduler ticks until ctx is done. Every instance ticks, the advisory lock
+// taken in RunDueJobs decides which one fires the due runs.
+func RunScheduler(ctx context.Context) {
+	dp := GetAppDependency()
+
+	dom := domain.NewDomain(dp.cfg, dp.db, dp.log)
+	uc := usecase.NewUsecase(dp.cfg, dp.log, dom)
+
+	if err := uc.Scheduler.ValidateJobs(); err != nil {
+		log.Fatalf("invalid scheduler config: %v", err)
+	}
+
+	interval := schedulerModels.DefaultTickInterval
+	if dp.cfg.Scheduler.TickIntervalInSeconds > 0 {
+		interval = time.Duration(dp.cfg.Scheduler.TickIntervalInSeconds) * time.Second
+	}
+
+	dp.log.InfoWithContext(ctx, "Scheduler started, ticking every "+interval.String())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := uc.Scheduler.RunDueJobs(ctx, time.Now()); err != nil {
+			dp.log.ErrorWithContext(ctx, "Error running scheduler tick: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -5,15 +5,13 @@ import (
 	"eventdrivensystem/internal/domain"
 	"eventdrivensystem/internal/handler/rest"
 	"eventdrivensystem/internal/usecase"
+	"eventdrivensystem/pkg/health"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
-	"github.com/labstack/gommon/log"
 	"github.com/spf13/cobra"
 )
 
@@ -21,12 +19,17 @@ var apiServerCmd = &cobra.Command{
 	Use:   "api-server",
 	Short: "Runs the API server",
 	Run: func(cmd *cobra.Command, args []string) {
-		NewServer()
+		lc := newAppLifecycle()
+		NewServer(lc)
+		if err := lc.Run(); err != nil {
+			log.Fatal(err)
+		}
 	},
 }
 
-func NewServer() {
-	ctx := context.Background()
+// NewServer adds the API server to lc, it stops accepting connections and
+// waits for in-flight requests on shutdown
+func NewServer(lc *Lifecycle) {
 	e := echo.New()
 	e.Use(echoMiddleware.Recover())
 	dp := GetAppDependency()
@@ -35,24 +38,21 @@ func NewServer() {
 	uc := usecase.NewUsecase(dp.cfg, dp.log, dom)
 
 	rest.NewRouterHandler(e, dp.validator, uc).RegisterRoutes()
-
-	go func() {
-		address := fmt.Sprintf("%s:%d", dp.cfg.ApiServer.Host, dp.cfg.ApiServer.Port)
-		if err := e.Start(address); err != nil {
-			log.Info("shutting down the server -> ", err)
-		}
-	}()
-
-	// Wait for interrupt signal to gracefully shutdown the server with
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt, os.Kill, syscall.SIGTERM, syscall.SIGINT)
-	<-quit
-
-	log.Info("shut down started")
-	if err := e.Shutdown(ctx); err != nil && err != http.ErrServerClosed {
-		log.Errorf("error shutting down api server", err)
-	}
-
-	log.Info("shut down completed")
-
+	health.RegisterRoutes(e, newHealthChecker(dp))
+
+	lc.Append(Hook{
+		Name: "api-server",
+		OnStart: func(ctx context.Context) error {
+			address := fmt.Sprintf("%s:%d", dp.cfg.ApiServer.Host, dp.cfg.ApiServer.Port)
+			go func() {
+				if err := e.Start(address); err != nil && err != http.ErrServerClosed {
+					lc.Fail("api-server", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return e.Shutdown(ctx)
+		},
+	})
 }
@@ -2,12 +2,18 @@ package cmd
 
 import (
 	"context"
+	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/domain"
+	"eventdrivensystem/internal/events"
 	"eventdrivensystem/internal/handler/worker"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/internal/usecase"
+	"eventdrivensystem/pkg/health"
 	"eventdrivensystem/pkg/logger/middleware"
 	"fmt"
+	"log"
 	"net/http"
-	"os"
-	"os/signal"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/hibiken/asynqmon"
@@ -17,33 +23,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// asynqDefaultQueue is where asynq puts a task enqueued without a queue
+const asynqDefaultQueue = "default"
+
 var asynqWorkerCmd = &cobra.Command{
 	Use:   "asynq-worker",
 	Short: "Start the worker service",
 	Run: func(cmd *cobra.Command, args []string) {
-		go StartWorkerService()
-		StartWorker()
+		lc := newAppLifecycle()
+		StartWorkerService(lc)
+		StartWorker(lc)
+		if err := lc.Run(); err != nil {
+			log.Fatal(err)
+		}
 	},
 }
 
-func StartWorkerService() {
-	var srv http.Server
-
+// StartWorkerService adds the asynq monitoring server to lc
+func StartWorkerService(lc *Lifecycle) {
 	dp := GetAppDependency()
 
-	idleConnection := make(chan struct{})
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt)
-		<-sigint
-
-		dp.log.Info("[WORKER-API] is shutting down")
-		if err := srv.Shutdown(context.Background()); err != nil {
-			dp.log.Info("[WORKER-API] Fail shutting down:", err)
-		}
-		close(idleConnection)
-	}()
-
 	e := echo.New()
 	e.Use(echoMiddleware.Recover())
 	asynqMon := asynqmon.New(asynqmon.Options{
@@ -52,31 +51,95 @@ func StartWorkerService() {
 	})
 
 	e.Any("/monitoring/tasks/*", echo.WrapHandler(asynqMon))
+	health.RegisterRoutes(e, newHealthChecker(dp))
 
-	go func() {
-		address := fmt.Sprintf("%s:%d", dp.cfg.AsyncQ.MonitoringHost, dp.cfg.AsyncQ.MonitoringPort)
-		if err := e.Start(address); err != nil {
-			dp.log.Info("shutting down the worker server -> ", err)
-		}
-	}()
+	lc.Append(Hook{
+		Name: "worker-monitoring",
+		OnStart: func(ctx context.Context) error {
+			address := fmt.Sprintf("%s:%d", dp.cfg.AsyncQ.MonitoringHost, dp.cfg.AsyncQ.MonitoringPort)
+			go func() {
+				if err := e.Start(address); err != nil && err != http.ErrServerClosed {
+					lc.Fail("worker-monitoring", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := e.Shutdown(ctx); err != nil {
+				return err
+			}
+			return asynqMon.Close()
+		},
+	})
 }
 
-func StartWorker() {
+// StartWorker adds the asynq worker to lc. On shutdown it stops pulling tasks and
+// gives the running ones the shutdown timeout to finish, unfinished tasks go back to the queue.
+func StartWorker(lc *Lifecycle) {
 	dp := GetAppDependency()
 
+	dom := domain.NewDomain(dp.cfg, dp.db, dp.log)
+	uc := usecase.NewUsecase(dp.cfg, dp.log, dom)
+
 	// Asynq Worker Setup
 	server := asynq.NewServer(
 		asynq.RedisClientOpt{Addr: dp.cfg.Redis.Address},
-		asynq.Config{Concurrency: 0},
+		asynq.Config{
+			Concurrency:     0,
+			Queues:          workerQueues(dp.cfg, dom.Routes),
+			ShutdownTimeout: time.Duration(dp.cfg.Lifecycle.ShutdownTimeoutInSeconds) * time.Second,
+		},
 	)
 
 	mux := asynq.NewServeMux()
 	mux.Use(middleware.LoggingMiddlewareAsynq(dp.log))
-	worker.NewWorkerHandler(dp.cfg, dp.log, mux).RegisterHandlers()
 
-	dp.log.Info("Worker started, waiting for tasks...")
-	if err := server.Run(mux); err != nil {
-		dp.log.Error("Could not start worker: %v", err)
-		return
+	worker.NewWorkerHandler(dp.cfg, dp.log, mux, uc).RegisterHandlers()
+
+	lc.Append(Hook{
+		Name: "asynq-worker",
+		OnStart: func(ctx context.Context) error {
+			if err := server.Start(mux); err != nil {
+				return err
+			}
+			dp.log.Info("Worker started, waiting for tasks...")
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				server.Shutdown()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+// workerQueues lists every asynq queue the application enqueues to, the event
+// routes including the saga steps and the scheduler jobs. asynq only pulls from
+// the queues of its config, a task in any other queue would never run.
+func workerQueues(cfg *configs.AppConfig, routes *events.Registry) map[string]int {
+	queues := map[string]int{asynqDefaultQueue: 1}
+
+	for _, topic := range routes.Topics(outboxModels.OutboxDestinationTypeAsynq) {
+		queues[topic] = 1
 	}
+
+	for _, job := range cfg.Scheduler.Jobs {
+		if job.Topic == "" {
+			continue
+		}
+		if job.Destination == "" || job.Destination == outboxModels.OutboxDestinationTypeAsynq {
+			queues[job.Topic] = 1
+		}
+	}
+
+	return queues
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/events"
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestWorkerQueuesCoverEveryAsynqTopic(t *testing.T) {
+	routes := events.NewRegistry()
+	routes.Register(&asynqModels.AsynqSendVerificationPayload{}, events.Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+		Topic:       "emails",
+	})
+	routes.Register(&asynqModels.AsynqSendNotificationPayload{}, events.Route{
+		Destination: outboxModels.OutboxDestinationTypeKafka,
+		Topic:       "notifications",
+	})
+	routes.Register(&asynqModels.AsynqSendOnboardingTipPayload{}, events.Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+	})
+
+	cfg := &configs.AppConfig{Scheduler: configs.Scheduler{Jobs: []configs.SchedulerJob{
+		{Name: "digest", Topic: "digests"},
+		{Name: "export", Destination: outboxModels.OutboxDestinationTypeKafka, Topic: "exports"},
+		{Name: "cleanup"},
+	}}}
+
+	assert.DeepEqual(t, workerQueues(cfg, routes), map[string]int{
+		asynqDefaultQueue: 1,
+		"emails":          1,
+		"digests":         1,
+	})
+}
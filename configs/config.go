@@ -1,11 +1,13 @@
 package configs
 
 import (
+	"fmt"
+	"os"
+	"strings"
 	"sync"
 
 	goValidator "github.com/go-playground/validator/v10"
 	"github.com/labstack/gommon/log"
-	"github.com/spf13/viper"
 )
 
 var (
@@ -14,18 +16,34 @@ var (
 )
 
 type AppConfig struct {
-	Meta      Meta
-	ApiServer ApiServer
-	SQL       SQL
-	Redis     Redis
-	Outbox    Outbox
-	AsyncQ    AsyncQ
+	Meta         Meta
+	Log          Log
+	Lifecycle    Lifecycle
+	ApiServer    ApiServer
+	SQL          SQL
+	Redis        Redis
+	Outbox       Outbox
+	AsyncQ       AsyncQ
+	Auth         Auth
+	Verification Verification
+	Onboarding   Onboarding
+	Scheduler    Scheduler
 }
 
 type Meta struct {
 	Name string
 }
 
+type Log struct {
+	// Level is one of trace, debug, info, warn, error, fatal or panic, info when empty
+	Level string `validate:"omitempty,oneof=trace debug info warn error fatal panic"`
+}
+
+type Lifecycle struct {
+	// ShutdownTimeoutInSeconds bounds how long stopping all components of a process may take
+	ShutdownTimeoutInSeconds int `validate:"min=1"`
+}
+
 type ApiServer struct {
 	Host string `validate:"required"`
 	Port int    `validate:"required"`
@@ -33,17 +51,98 @@ type ApiServer struct {
 
 type SQL struct {
 	DSN string `validate:"required"`
+	// ReplicaDSNs are read replicas, reads outside a transaction go to one of them
+	ReplicaDSNs []string
+
+	// the pool settings apply to the primary and to every replica
+	MaxOpenConns             int `validate:"min=1"`
+	MaxIdleConns             int `validate:"min=0"`
+	ConnMaxLifetimeInSeconds int `validate:"min=0"`
+	ConnMaxIdleTimeInSeconds int `validate:"min=0"`
+
+	// StatementTimeoutInMs aborts statements running longer on the server, 0 disables it
+	StatementTimeoutInMs int `validate:"min=0"`
+
+	// MigrationsPath is a golang-migrate source URL like file://./docs/db/migrations,
+	// the migrations embedded in the binary are used when it is empty
+	MigrationsPath string
 }
 
 type Redis struct {
 	Address string `validate:"required"`
 }
 
+// defaults are used for the keys no source sets
+var defaults = map[string]interface{}{
+	"Lifecycle.ShutdownTimeoutInSeconds":        30,
+	"SQL.MaxOpenConns":                          30,
+	"SQL.MaxIdleConns":                          5,
+	"SQL.ConnMaxLifetimeInSeconds":              620,
+	"SQL.ConnMaxIdleTimeInSeconds":              300,
+	"Outbox.MaxRetries":                         3,
+	"Outbox.MaxConcurrency":                     10,
+	"Outbox.MaxBatchSize":                       100,
+	"Outbox.DurationIntervalInMs":               1000,
+	"Outbox.HeartbeatTimeoutInSeconds":          60,
+	"Outbox.ResultFlushSize":                    500,
+	"Outbox.PublishBatchSize":                   100,
+	"Outbox.AsynqEnqueueConcurrency":            16,
+	"Outbox.MaxIdleIntervalInMs":                10000,
+	"Outbox.CircuitBreakerFailureThreshold":     5,
+	"Outbox.CircuitBreakerOpenTimeoutInSeconds": 30,
+	"Outbox.AttemptRetentionInHours":            168,
+	"Outbox.ResultFlushIntervalInMs":            200,
+	"Outbox.ProcessingLeaseInSeconds":           300,
+}
+
 type Outbox struct {
 	MaxRetries           int
-	MaxConcurrency       int
-	MaxBatchSize         int
-	DurationIntervalInMs int
+	MaxConcurrency       int `validate:"min=1"`
+	MaxBatchSize         int `validate:"min=1"`
+	DurationIntervalInMs int `validate:"min=1"`
+	// MaxIdleIntervalInMs caps the polling interval, which doubles from
+	// DurationIntervalInMs while fetches come back empty. It has to stay below
+	// HeartbeatTimeoutInSeconds or an idle relay is reported as not ready.
+	MaxIdleIntervalInMs int `validate:"min=1"`
+	// PublishBatchSize is how many fetched rows are published to their destination in one batch
+	PublishBatchSize int `validate:"min=1"`
+	// AsynqEnqueueConcurrency caps the enqueues of a batch that are in flight at once
+	AsynqEnqueueConcurrency int `validate:"min=1"`
+
+	// HealthHost and HealthPort serve /healthz and /readyz of the outbox worker, a zero port disables them
+	HealthHost string
+	HealthPort int
+	// HeartbeatTimeoutInSeconds is how long the relay loop may go without an iteration before it is not ready
+	HeartbeatTimeoutInSeconds int `validate:"min=1"`
+	// ResultFlushSize and ResultFlushIntervalInMs bound how many publish results
+	// are written back in one statement and how long the first of them waits
+	ResultFlushSize         int `validate:"min=1"`
+	ResultFlushIntervalInMs int `validate:"min=1"`
+	// ProcessingLeaseInSeconds is how long a row may stay PROCESSING before the
+	// relay requeues it. It must cover a 30s publish, the result flush interval and
+	// two result writes of up to 3 tries of 30s each, about 212s with the default
+	// interval, the relay refuses to start with a shorter lease.
+	ProcessingLeaseInSeconds int `validate:"min=300"`
+
+	// CircuitBreakerFailureThreshold consecutive publish failures to a destination
+	// defer its rows for CircuitBreakerOpenTimeoutInSeconds, then one row probes it
+	CircuitBreakerFailureThreshold     int               `validate:"min=1"`
+	CircuitBreakerOpenTimeoutInSeconds int               `validate:"min=1"`
+	RateLimits                         []OutboxRateLimit `validate:"dive"`
+
+	// WorkerID names the relay instance in the attempt history, host name and pid when empty
+	WorkerID string
+	// AttemptRetentionInHours is how long the attempt history is kept, 0 keeps it forever
+	AttemptRetentionInHours int `validate:"min=0"`
+}
+
+// OutboxRateLimit is a token bucket for the rows of a destination type or of an event type
+type OutboxRateLimit struct {
+	Destination string `validate:"required_without=EventType,excluded_with=EventType"`
+	EventType   string
+	PerSecond   float64 `validate:"gt=0"`
+	// Burst is PerSecond rounded up when 0
+	Burst int `validate:"min=0"`
 }
 
 type AsyncQ struct {
@@ -53,6 +152,53 @@ type AsyncQ struct {
 	MonitoringPort          int    `validate:"required"`
 }
 
+type Auth struct {
+	JWTSecret                    string `validate:"required"`
+	Issuer                       string
+	AccessTokenDurationInMinutes int `validate:"required"`
+	RefreshTokenDurationInHours  int `validate:"required"`
+
+	PasswordResetTokenDurationInMinutes int `validate:"required"`
+	PasswordResetCooldownInSeconds      int
+
+	// AdminUserIDs may use the /v1/admin endpoints
+	AdminUserIDs []string
+}
+
+type Verification struct {
+	TokenDurationInHours    int `validate:"required"`
+	ResendCooldownInSeconds int
+	MaxResendPerHour        int
+}
+
+type Onboarding struct {
+	// TipDelayInHours is how long after sign up the onboarding tip is sent, 0 disables it
+	TipDelayInHours int
+	// SagaEnabled starts the user_onboarding saga on sign up. Its compensation
+	// deletes the new user when billing can't be provisioned, so it is off by default.
+	SagaEnabled bool
+}
+
+type Scheduler struct {
+	TickIntervalInSeconds int
+	// MaxCatchUpRuns bounds how many missed runs of a job are looked at after downtime
+	MaxCatchUpRuns int
+	Jobs           []SchedulerJob `validate:"dive"`
+}
+
+type SchedulerJob struct {
+	Name string `validate:"required"`
+	// Spec is a standard 5 field cron expression, prefix it with CRON_TZ=<zone> for a non-UTC zone
+	Spec      string `validate:"required"`
+	EventType string `validate:"required"`
+	// Destination is an outbox destination type, ASYNQ when empty
+	Destination string
+	Topic       string
+	// MissedRunPolicy is CATCH_UP or SKIP, SKIP when empty
+	MissedRunPolicy string `validate:"omitempty,oneof=CATCH_UP SKIP"`
+	Params          map[string]interface{}
+}
+
 func Get() *AppConfig {
 
 	if cfg == nil {
@@ -62,38 +208,95 @@ func Get() *AppConfig {
 	return cfg
 }
 
+// Load reads the config of the process once from DefaultSources, it exits when the config is invalid
 func Load() {
 	onceCfg.Do(func() {
-		v := viper.New()
-		v.AddConfigPath(".")
-		v.SetConfigType("yaml")
-		v.SetConfigName("config")
-
-		err := v.ReadInConfig()
+		c, err := LoadFrom(DefaultSources(configFile, os.Getenv(EnvProfile))...)
 		if err != nil {
-			log.Fatalf("error reading config file, %v", err)
+			log.Fatalf("error loading config, %v", err)
 		}
 
-		c := &AppConfig{}
-		err = v.Unmarshal(&c)
-		if err != nil {
-			log.Fatalf("unable to decode into struct, %v", err)
-		}
+		cfg = c
+		live.Store(c)
+	})
+}
+
+// LoadFrom reads the sources in order, later sources win, and validates the
+// result. Every invalid field is reported in one *ValidationError.
+func LoadFrom(sources ...Source) (*AppConfig, error) {
+	v, err := newViper(sources...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &AppConfig{}
+	if err := v.Unmarshal(c); err != nil {
+		return nil, fmt.Errorf("unable to decode into struct, %w", err)
+	}
+
+	if err := Validate(c); err != nil {
+		return nil, err
+	}
 
-		// Perform validation
-		validate := goValidator.New()
-		if err := validate.Struct(c); err != nil {
-			if validationErrors, ok := err.(goValidator.ValidationErrors); ok {
-				for _, ve := range validationErrors {
-					log.Printf("Validation error for field '%s': %s", ve.StructNamespace(), ve.Tag())
-				}
-			} else {
-				log.Fatalf("Validation error: %v", err)
-			}
-			log.Fatalf("Invalid config")
+	return c, nil
+}
+
+// FieldError is one failed validation rule, Field is the dotted config key
+type FieldError struct {
+	Field string
+	Rule  string
+}
+
+func (e FieldError) String() string {
+	return e.Field + " failed on " + e.Rule
+}
+
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		lines[i] = f.String()
+	}
+	return "invalid config: " + strings.Join(lines, "; ")
+}
+
+// Validate checks the validate tags of c and the rules between settings
+func Validate(c *AppConfig) error {
+	validate := goValidator.New()
+	validate.RegisterStructValidation(validateOutbox, Outbox{})
+
+	err := validate.Struct(c)
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(goValidator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	verr := &ValidationError{}
+	for _, ve := range validationErrors {
+		rule := ve.Tag()
+		if ve.Param() != "" {
+			rule += "=" + ve.Param()
 		}
+		verr.Fields = append(verr.Fields, FieldError{
+			Field: strings.TrimPrefix(ve.Namespace(), "AppConfig."),
+			Rule:  rule,
+		})
+	}
+	return verr
+}
 
-		cfg = c
+// validateOutbox checks the outbox settings that depend on each other
+func validateOutbox(sl goValidator.StructLevel) {
+	o := sl.Current().Interface().(Outbox)
 
-	})
+	if o.HeartbeatTimeoutInSeconds > 0 && o.MaxIdleIntervalInMs >= o.HeartbeatTimeoutInSeconds*1000 {
+		sl.ReportError(o.MaxIdleIntervalInMs, "MaxIdleIntervalInMs", "MaxIdleIntervalInMs", "lt_heartbeat_timeout", "")
+	}
 }
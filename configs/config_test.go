@@ -0,0 +1,60 @@
+package configs
+
+import (
+	goErrors "errors"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const validConfig = `
+ApiServer:
+  Host: localhost
+  Port: 5001
+SQL:
+  DSN: postgres://localhost
+Redis:
+  Address: localhost:6379
+AsyncQ:
+  BasedServiceConsumerURL: http://localhost:8080
+  MonitoringHost: localhost
+  MonitoringPort: 8081
+Auth:
+  JWTSecret: secret
+  AccessTokenDurationInMinutes: 15
+  RefreshTokenDurationInHours: 720
+  PasswordResetTokenDurationInMinutes: 30
+Verification:
+  TokenDurationInHours: 24
+`
+
+func TestLoadFromAppliesOutboxDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, path, validConfig+"Outbox:\n  MaxBatchSize: 500\n")
+
+	c, err := LoadFrom(File(path))
+	assert.NilError(t, err)
+
+	assert.Equal(t, c.Outbox.MaxConcurrency, 10)
+	assert.Equal(t, c.Outbox.MaxBatchSize, 500)
+	assert.Equal(t, c.Outbox.DurationIntervalInMs, 1000)
+}
+
+func TestLoadFromReportsEveryInvalidField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, path, "Outbox:\n  MaxConcurrency: 0\nLog:\n  Level: loud\n")
+
+	_, err := LoadFrom(File(path))
+
+	var verr *ValidationError
+	assert.Assert(t, goErrors.As(err, &verr))
+	assert.Equal(t, len(verr.Fields), 14)
+	assert.DeepEqual(t, verr.Fields[0], FieldError{Field: "Log.Level", Rule: "oneof=trace debug info warn error fatal panic"})
+	assert.ErrorContains(t, err, "Outbox.MaxConcurrency failed on min=1")
+}
+
+func TestLoadFromMissingFile(t *testing.T) {
+	_, err := LoadFrom(File(filepath.Join(t.TempDir(), "missing.yml")))
+	assert.ErrorContains(t, err, "read config file")
+}
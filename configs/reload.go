@@ -0,0 +1,154 @@
+package configs
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/labstack/gommon/log"
+)
+
+// liveKeys are the settings Reload applies to a running process, a change to
+// any other key is logged and ignored until the next restart
+var liveKeys = map[string]bool{
+	"Log.Level":                   true,
+	"Outbox.MaxConcurrency":       true,
+	"Outbox.MaxBatchSize":         true,
+	"Outbox.DurationIntervalInMs": true,
+	"Outbox.MaxIdleIntervalInMs":  true,
+	"Outbox.MaxRetries":           true,
+}
+
+var (
+	live atomic.Pointer[AppConfig]
+
+	reloadMu    sync.Mutex
+	subscribers []func(c *AppConfig)
+)
+
+// Current returns the config with the latest reloaded live settings. Read it
+// where a live setting is used instead of keeping the value around.
+func Current() *AppConfig {
+	if c := live.Load(); c != nil {
+		return c
+	}
+	return Get()
+}
+
+// OnReload registers fn to run after Reload swapped in new live settings
+func OnReload(fn func(c *AppConfig)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	subscribers = append(subscribers, fn)
+}
+
+// Watch reloads the config every time the process receives SIGHUP, until ctx is done
+func Watch(ctx context.Context) {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				if err := Reload(); err != nil {
+					log.Errorf("config reload rejected, %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Reload reads the config sources again and swaps in the changed live settings.
+// An invalid config is rejected as a whole, changes to settings outside
+// liveKeys are logged and left as they are.
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	next, err := LoadFrom(DefaultSources(configFile, os.Getenv(EnvProfile))...)
+	if err != nil {
+		return err
+	}
+
+	updated, applied, err := mergeLive(Current(), next)
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Printf("config reloaded, no live setting changed")
+		return nil
+	}
+
+	live.Store(updated)
+	for _, fn := range subscribers {
+		fn(updated)
+	}
+
+	log.Printf("config reloaded, applied %s", strings.Join(applied, ", "))
+	return nil
+}
+
+// mergeLive copies the changed live settings of next into a copy of current
+// and returns it with the applied keys. next is valid on its own, but its live
+// settings may not fit the settings current keeps until the restart, the merged
+// config is validated again and rejected as a whole.
+func mergeLive(current, next *AppConfig) (*AppConfig, []string, error) {
+	updated := *current
+	var applied []string
+
+	for _, key := range changedKeys(reflect.ValueOf(*current), reflect.ValueOf(*next), "") {
+		if !liveKeys[key] {
+			log.Printf("config %s changed, it is applied on the next restart", key)
+			continue
+		}
+
+		path := strings.Split(key, ".")
+		dst := reflect.ValueOf(&updated).Elem()
+		src := reflect.ValueOf(next).Elem()
+		for _, name := range path {
+			dst = dst.FieldByName(name)
+			src = src.FieldByName(name)
+		}
+		dst.Set(src)
+
+		applied = append(applied, key)
+	}
+
+	if len(applied) > 0 {
+		if err := Validate(&updated); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &updated, applied, nil
+}
+
+// changedKeys lists the dotted keys whose value differs between a and b, a
+// struct is compared field by field and anything else as a whole
+func changedKeys(a, b reflect.Value, prefix string) []string {
+	var keys []string
+
+	for i := 0; i < a.NumField(); i++ {
+		key := prefix + a.Type().Field(i).Name
+
+		if a.Field(i).Kind() == reflect.Struct {
+			keys = append(keys, changedKeys(a.Field(i), b.Field(i), key+".")...)
+			continue
+		}
+
+		if !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
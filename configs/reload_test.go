@@ -0,0 +1,69 @@
+package configs
+
+import (
+	goErrors "errors"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func loadValidConfig(t *testing.T) *AppConfig {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yml")
+	writeFile(t, path, validConfig)
+
+	c, err := LoadFrom(File(path))
+	assert.NilError(t, err)
+	return c
+}
+
+func TestMergeLiveAppliesOnlyLiveSettings(t *testing.T) {
+	current := loadValidConfig(t)
+	current.Log.Level = "info"
+	current.Outbox.MaxRetries = 3
+	current.Outbox.MaxConcurrency = 10
+
+	next := *current
+	next.Log.Level = "debug"
+	next.SQL.DSN = "postgres://new"
+	next.Outbox.MaxRetries = 5
+	next.Outbox.MaxConcurrency = 20
+
+	updated, applied, err := mergeLive(current, &next)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, applied, []string{"Log.Level", "Outbox.MaxRetries", "Outbox.MaxConcurrency"})
+	assert.Equal(t, updated.Log.Level, "debug")
+	assert.Equal(t, updated.Outbox.MaxConcurrency, 20)
+	assert.Equal(t, updated.Outbox.MaxRetries, 5)
+	assert.Equal(t, updated.SQL.DSN, "postgres://localhost")
+	assert.Equal(t, current.Log.Level, "info")
+}
+
+func TestMergeLiveWithoutChanges(t *testing.T) {
+	current := &AppConfig{Scheduler: Scheduler{Jobs: []SchedulerJob{{Name: "a"}}}}
+	next := &AppConfig{Scheduler: Scheduler{Jobs: []SchedulerJob{{Name: "a"}}}}
+
+	_, applied, err := mergeLive(current, next)
+	assert.NilError(t, err)
+	assert.Equal(t, len(applied), 0)
+}
+
+func TestMergeLiveRejectsAnInvalidMerge(t *testing.T) {
+	current := loadValidConfig(t)
+
+	// next is valid with its own heartbeat timeout, which is only applied on restart
+	next := *current
+	next.Outbox.HeartbeatTimeoutInSeconds = 120
+	next.Outbox.MaxIdleIntervalInMs = 90000
+	assert.NilError(t, Validate(&next))
+
+	updated, _, err := mergeLive(current, &next)
+	assert.Assert(t, updated == nil)
+
+	var verr *ValidationError
+	assert.Assert(t, goErrors.As(err, &verr))
+	assert.DeepEqual(t, verr.Fields, []FieldError{{Field: "Outbox.MaxIdleIntervalInMs", Rule: "lt_heartbeat_timeout"}})
+	assert.Equal(t, current.Outbox.MaxIdleIntervalInMs, 10000)
+}
@@ -0,0 +1,165 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/gommon/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	DefaultConfigFile = "config.yml"
+
+	// EnvPrefix prefixes the environment variables that override config keys,
+	// SQL.DSN is read from APP_SQL_DSN and Auth.JWTSecret from APP_AUTH_JWTSECRET
+	EnvPrefix = "APP"
+	// EnvProfile selects the overlay file, APP_ENV=production layers config.production.yml over config.yml
+	EnvProfile = EnvPrefix + "_ENV"
+	// fileSuffix reads a key from a file instead, e.g. APP_SQL_DSN_FILE=/run/secrets/dsn
+	fileSuffix = "_FILE"
+)
+
+var configFile = DefaultConfigFile
+
+// SetConfigFile sets the base config file read by Load, it must be called before the first Load or Get
+func SetConfigFile(path string) {
+	if path != "" {
+		configFile = path
+	}
+}
+
+// Source adds values to the config being loaded, see LoadFrom
+type Source func(v *viper.Viper) error
+
+// File merges a yaml file, it is an error when the file can't be read
+func File(path string) Source {
+	return func(v *viper.Viper) error {
+		v.SetConfigFile(path)
+		if err := v.MergeInConfig(); err != nil {
+			return fmt.Errorf("read config file %s: %w", path, err)
+		}
+		return nil
+	}
+}
+
+// OptionalFile merges a yaml file when it exists
+func OptionalFile(path string) Source {
+	return func(v *viper.Viper) error {
+		if _, err := os.Stat(path); err != nil {
+			log.Printf("no config file %s, skipping it", path)
+			return nil
+		}
+		return File(path)(v)
+	}
+}
+
+// Env binds every config key to its environment variable and secret file
+func Env() Source {
+	return func(v *viper.Viper) error {
+		v.SetEnvPrefix(EnvPrefix)
+		v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+
+		for _, key := range configKeys(reflect.TypeOf(AppConfig{}), "") {
+			if err := v.BindEnv(key); err != nil {
+				return err
+			}
+
+			value, ok, err := readSecretFile(key)
+			if err != nil {
+				return err
+			}
+			if ok {
+				v.Set(key, value)
+			}
+		}
+
+		return nil
+	}
+}
+
+// DefaultSources are the sources the commands load: the base file and the profile
+// overlay next to it when they exist and the environment. Later sources win:
+// base file, overlay file, environment variable, secret file. Without files the
+// environment has to set every required key, validation reports the missing ones.
+func DefaultSources(path, profile string) []Source {
+	sources := []Source{OptionalFile(path)}
+	if profile != "" {
+		sources = append(sources, OptionalFile(overlayPath(path, profile)))
+	}
+	return append(sources, Env())
+}
+
+// newViper applies the defaults and then every source in order
+func newViper(sources ...Source) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
+
+	for _, source := range sources {
+		if err := source(v); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// overlayPath turns config.yml into config.<profile>.yml
+func overlayPath(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// configKeys lists the dotted keys of every scalar and scalar slice of t.
+// Slices of structs, like Scheduler.Jobs, can only be set from a file.
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := prefix + field.Name
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = append(keys, configKeys(field.Type, key+".")...)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct:
+		case field.Type.Kind() == reflect.Map:
+		default:
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+func envName(key string) string {
+	return EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// readSecretFile returns the content of the file named by the _FILE variable of key
+func readSecretFile(key string) (string, bool, error) {
+	name := envName(key) + fileSuffix
+
+	path, ok := os.LookupEnv(name)
+	if !ok || path == "" {
+		return "", false, nil
+	}
+
+	if _, ok := os.LookupEnv(envName(key)); ok {
+		return "", false, fmt.Errorf("both %s and %s are set, use only one", envName(key), name)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("read %s: %w", name, err)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
@@ -0,0 +1,79 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NilError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestNewViperLayers(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yml")
+
+	writeFile(t, base, `
+SQL:
+  DSN: postgres://base
+ApiServer:
+  Host: localhost
+  Port: 5001
+Redis:
+  Address: localhost:6379
+Auth:
+  JWTSecret: from-file
+`)
+	writeFile(t, filepath.Join(dir, "config.production.yml"), `
+ApiServer:
+  Port: 8080
+`)
+	writeFile(t, filepath.Join(dir, "jwt_secret"), "from-secret-file\n")
+
+	t.Setenv("APP_REDIS_ADDRESS", "redis:6379")
+	t.Setenv("APP_AUTH_JWTSECRET_FILE", filepath.Join(dir, "jwt_secret"))
+	t.Setenv("APP_AUTH_ADMINUSERIDS", "a,b")
+
+	v, err := newViper(DefaultSources(base, "production")...)
+	assert.NilError(t, err)
+
+	c := &AppConfig{}
+	assert.NilError(t, v.Unmarshal(c))
+
+	assert.Equal(t, c.SQL.DSN, "postgres://base")
+	assert.Equal(t, c.ApiServer.Host, "localhost")
+	assert.Equal(t, c.ApiServer.Port, 8080)
+	assert.Equal(t, c.Redis.Address, "redis:6379")
+	assert.Equal(t, c.Auth.JWTSecret, "from-secret-file")
+	assert.DeepEqual(t, c.Auth.AdminUserIDs, []string{"a", "b"})
+}
+
+func TestDefaultSourcesWithoutFiles(t *testing.T) {
+	t.Setenv("APP_SQL_DSN", "postgres://env")
+
+	v, err := newViper(DefaultSources(filepath.Join(t.TempDir(), "config.yml"), "production")...)
+	assert.NilError(t, err)
+
+	c := &AppConfig{}
+	assert.NilError(t, v.Unmarshal(c))
+	assert.Equal(t, c.SQL.DSN, "postgres://env")
+
+	_, err = LoadFrom(DefaultSources(filepath.Join(t.TempDir(), "config.yml"), "")...)
+	assert.ErrorContains(t, err, "ApiServer.Host failed on required")
+}
+
+func TestNewViperSecretConflict(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yml")
+	writeFile(t, base, "SQL:\n  DSN: postgres://base\n")
+
+	t.Setenv("APP_SQL_DSN", "postgres://env")
+	t.Setenv("APP_SQL_DSN_FILE", filepath.Join(dir, "dsn"))
+
+	_, err := newViper(DefaultSources(base, "")...)
+	assert.ErrorContains(t, err, "both APP_SQL_DSN and APP_SQL_DSN_FILE are set")
+}
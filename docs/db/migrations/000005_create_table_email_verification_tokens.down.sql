@@ -0,0 +1,2 @@
+drop table email_verification_tokens;
+ALTER TABLE users DROP COLUMN verified_at;
\ No newline at end of file
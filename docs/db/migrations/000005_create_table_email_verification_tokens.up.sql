@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN verified_at TIMESTAMP WITH TIME ZONE NULL;
+
+CREATE TABLE email_verification_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,           -- SHA-256 of the token sent by email
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE NULL,            -- Set once the token is consumed or superseded
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_email_verification_tokens_user ON email_verification_tokens (user_id, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX idx_users_created_at;
+ALTER TABLE users DROP COLUMN phone_number;
+ALTER TABLE users DROP COLUMN full_name;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN full_name VARCHAR(255) NULL;
+ALTER TABLE users ADD COLUMN phone_number VARCHAR(50) NULL;
+
+CREATE INDEX idx_users_created_at ON users (created_at) WHERE deleted_at IS NULL;
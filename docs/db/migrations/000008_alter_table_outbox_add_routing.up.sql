@@ -0,0 +1,4 @@
+ALTER TABLE outbox ADD COLUMN topic VARCHAR(255) NULL;          -- Broker topic or asynq queue, NULL uses the destination default
+ALTER TABLE outbox ADD COLUMN ordering_key VARCHAR(255) NULL;   -- Rows sharing a key are relayed one after another
+
+CREATE INDEX idx_outbox_ordering_key ON outbox (ordering_key, created_at) WHERE ordering_key IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Rows of the default partition fall outside every monthly partition, there is nowhere to move them
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM outbox_default) THEN
+        RAISE EXCEPTION 'outbox_default still has rows, create monthly partitions for them or remove them before migrating down';
+    END IF;
+END $$;
+
+DROP TABLE IF EXISTS outbox_default;
+DROP INDEX idx_outbox_dedup_key;
+ALTER TABLE outbox DROP COLUMN dedup_key;
@@ -0,0 +1,6 @@
+ALTER TABLE outbox ADD COLUMN dedup_key VARCHAR(255) NULL;      -- Idempotency key of a scheduled event, used to skip duplicates and to cancel it
+
+CREATE INDEX idx_outbox_dedup_key ON outbox (dedup_key) WHERE dedup_key IS NOT NULL AND status = 'PENDING';
+
+-- Scheduled events may fire after the last monthly partition, keep them instead of failing the insert
+CREATE TABLE IF NOT EXISTS outbox_default PARTITION OF outbox DEFAULT;
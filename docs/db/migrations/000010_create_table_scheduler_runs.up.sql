@@ -0,0 +1,11 @@
+CREATE TABLE scheduler_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_name VARCHAR(255) NOT NULL,                  -- Name of the job in the Scheduler config
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL,  -- Time the cron spec fired for, not when it was processed
+    status VARCHAR(50) NOT NULL,                     -- FIRED or SKIPPED
+    outbox_id UUID NULL,                             -- Outbox row written for a FIRED run
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A run is handled once, whichever instance processes it
+CREATE UNIQUE INDEX idx_scheduler_runs_job_scheduled_at ON scheduler_runs (job_name, scheduled_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE saga_steps;
+DROP TABLE sagas;
\ No newline at end of file
@@ -0,0 +1,28 @@
+CREATE TABLE sagas (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,                      -- Name of the saga definition
+    status VARCHAR(50) NOT NULL,                     -- RUNNING, COMPENSATING, COMPLETED, COMPENSATED or FAILED
+    current_step INT NOT NULL DEFAULT 0,             -- Step being executed or compensated
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,         -- Saga input, merged with the output of every step
+    error_message TEXT,                              -- Why the saga is compensating or failed
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sagas_status ON sagas (status, updated_at);
+
+CREATE TABLE saga_steps (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    saga_id UUID NOT NULL REFERENCES sagas (id) ON DELETE CASCADE,
+    step_index INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,                     -- PENDING, RUNNING, SUCCEEDED, FAILED, TIMED_OUT, COMPENSATING, COMPENSATED or COMPENSATION_FAILED
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE,
+    deadline_at TIMESTAMP WITH TIME ZONE,            -- The step times out when no reply arrived by then
+    finished_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_saga_steps_saga_step ON saga_steps (saga_id, step_index);
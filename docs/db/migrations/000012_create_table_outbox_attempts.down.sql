@@ -0,0 +1 @@
+DROP TABLE outbox_attempts;
\ No newline at end of file
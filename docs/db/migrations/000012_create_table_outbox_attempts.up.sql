@@ -0,0 +1,20 @@
+CREATE TABLE outbox_attempts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    outbox_id UUID NOT NULL,                         -- No foreign key, the outbox key includes execute_at which moves on retry
+    attempt INT NOT NULL,                            -- Attempt count of the outbox row for this attempt
+    event_type VARCHAR(255) NOT NULL,
+    destination_type VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL,                     -- Outcome: SENT, RETRYING, FAILED or DEFERRED
+    worker_id VARCHAR(255) NOT NULL,                 -- Relay instance that made the attempt
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    response TEXT,                                   -- What the destination answered
+    error_message TEXT,
+    backoff_ms BIGINT,                               -- Wait chosen before the next attempt
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_attempts_outbox_id ON outbox_attempts (outbox_id, started_at);
+
+-- Retention deletes by age
+CREATE INDEX idx_outbox_attempts_finished_at ON outbox_attempts (finished_at);
@@ -0,0 +1,2 @@
+DROP INDEX idx_outbox_processing_started_at;
+ALTER TABLE outbox DROP COLUMN processing_started_at;
@@ -0,0 +1,6 @@
+ALTER TABLE outbox ADD COLUMN processing_started_at TIMESTAMP WITH TIME ZONE NULL;     -- Lease of a PROCESSING row, the reaper requeues it once the lease expires
+
+-- Rows picked before the lease existed get a fresh one
+UPDATE outbox SET processing_started_at = CURRENT_TIMESTAMP WHERE status = 'PROCESSING';
+
+CREATE INDEX idx_outbox_processing_started_at ON outbox (processing_started_at) WHERE status = 'PROCESSING';
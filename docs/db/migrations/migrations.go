@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations so that the binary can migrate
+// a database without this directory next to it
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
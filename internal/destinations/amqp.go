@@ -0,0 +1,81 @@
+package destinations
+
+import (
+	"context"
+	goErrors "errors"
+
+	models "eventdrivensystem/internal/models/outbox"
+)
+
+var ErrAMQPNacked = goErrors.New("message nacked by the broker")
+
+// AMQPMessage is one message published to an exchange
+type AMQPMessage struct {
+	Exchange   string
+	RoutingKey string
+	MessageID  string
+	Headers    map[string]interface{}
+	Body       []byte
+}
+
+// AMQPConfirmation is the pending publisher confirm of one message, like the
+// DeferredConfirmation of rabbitmq/amqp091-go
+type AMQPConfirmation interface {
+	// WaitContext blocks until the broker acks or nacks the message, it reports true on ack
+	WaitContext(ctx context.Context) (bool, error)
+}
+
+// AMQPChannel is a channel in confirm mode
+type AMQPChannel interface {
+	PublishWithDeferredConfirm(ctx context.Context, msg AMQPMessage) (AMQPConfirmation, error)
+}
+
+// AMQP publishes a whole batch before waiting for any confirm, so the batch
+// costs one round trip to the broker instead of one per message. The topic of
+// the row is the exchange and its event type the routing key.
+type AMQP struct {
+	channel AMQPChannel
+}
+
+func NewAMQP(channel AMQPChannel) *AMQP {
+	return &AMQP{channel: channel}
+}
+
+func (a *AMQP) PublishBatch(ctx context.Context, outboxes []models.Outbox) []Result {
+	results := make([]Result, len(outboxes))
+	confirms := make([]AMQPConfirmation, len(outboxes))
+
+	for i, outbox := range outboxes {
+		msg := AMQPMessage{
+			RoutingKey: outbox.EventType,
+			MessageID:  outbox.ID.String(),
+			Headers:    map[string]interface{}{"event_type": outbox.EventType},
+		}
+		if outbox.Topic != nil {
+			msg.Exchange = *outbox.Topic
+		}
+		if outbox.Payload != nil {
+			msg.Body = outbox.Payload.Bytes
+		}
+
+		confirms[i], results[i].Err = a.channel.PublishWithDeferredConfirm(ctx, msg)
+	}
+
+	for i, confirm := range confirms {
+		if results[i].Err != nil {
+			continue
+		}
+
+		acked, err := confirm.WaitContext(ctx)
+		switch {
+		case err != nil:
+			results[i].Err = err
+		case !acked:
+			results[i].Err = ErrAMQPNacked
+		default:
+			results[i].Response = "acked"
+		}
+	}
+
+	return results
+}
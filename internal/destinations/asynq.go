@@ -0,0 +1,149 @@
+package destinations
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	models "eventdrivensystem/internal/models/outbox"
+
+	"github.com/hibiken/asynq"
+)
+
+// AsynqEnqueuer enqueues one task, like asynq.Client
+type AsynqEnqueuer interface {
+	EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
+}
+
+// Asynq enqueues a batch as tasks. The asynq client has no multi-enqueue
+// command, so up to concurrency enqueues of a batch are in flight at once over
+// the pooled Redis connections and overlap their round trips.
+type Asynq struct {
+	client      AsynqEnqueuer
+	maxRetry    int
+	concurrency int
+}
+
+func NewAsynq(client AsynqEnqueuer, maxRetry, concurrency int) *Asynq {
+	return &Asynq{
+		client:      client,
+		maxRetry:    maxRetry,
+		concurrency: max(concurrency, 1),
+	}
+}
+
+func (a *Asynq) PublishBatch(ctx context.Context, outboxes []models.Outbox) []Result {
+	results := make([]Result, len(outboxes))
+
+	indexes := make(chan int, len(outboxes))
+	for i := range outboxes {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg := &sync.WaitGroup{}
+	for w := 0; w < min(a.concurrency, len(outboxes)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = a.enqueue(ctx, outboxes[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (a *Asynq) enqueue(ctx context.Context, outbox models.Outbox) Result {
+	// asynq rejects these before reaching Redis, retrying can't help
+	if strings.TrimSpace(outbox.EventType) == "" {
+		return Result{Err: Permanent(fmt.Errorf("outbox %s has no event type", outbox.ID))}
+	}
+	if outbox.Topic != nil && strings.TrimSpace(*outbox.Topic) == "" {
+		return Result{Err: Permanent(fmt.Errorf("outbox %s has an empty asynq queue", outbox.ID))}
+	}
+
+	opts := []asynq.Option{asynq.MaxRetry(a.maxRetry)}
+	if outbox.Topic != nil {
+		opts = append(opts, asynq.Queue(*outbox.Topic))
+	}
+
+	var payload []byte
+	if outbox.Payload != nil {
+		payload = outbox.Payload.Bytes
+	}
+
+	info, err := a.client.EnqueueContext(ctx, asynq.NewTask(outbox.EventType, payload), opts...)
+	if err != nil {
+		return asynqError(err)
+	}
+	return Result{Response: fmt.Sprintf("task %s enqueued to %s", info.ID, info.Queue)}
+}
+
+// redisReplyError is an error reply of Redis, like redis.Error of go-redis
+type redisReplyError interface {
+	error
+	RedisError()
+}
+
+// transientRedisReplies are the error replies Redis gives while it can't serve
+// a command for a while, with the wait worth asking for before the next try
+var transientRedisReplies = []struct {
+	prefix     string
+	retryAfter time.Duration
+}{
+	{prefix: "LOADING ", retryAfter: 5 * time.Second},
+	{prefix: "BUSY ", retryAfter: 5 * time.Second},
+	{prefix: "OOM ", retryAfter: 30 * time.Second},
+	{prefix: "ERR max number of clients reached", retryAfter: time.Second},
+	{prefix: "READONLY "},
+	{prefix: "MASTERDOWN "},
+	{prefix: "CLUSTERDOWN "},
+	{prefix: "TRYAGAIN "},
+}
+
+// asynqTaskRejections are the errors asynq returns for the task itself, the
+// same task fails the same way on every try
+var asynqTaskRejections = []string{
+	"cannot encode message",
+	"task typename cannot be empty",
+	"queue name must contain one or more characters",
+}
+
+// asynqError classifies an enqueue error. A duplicate means the task is already
+// enqueued, so the row is published. A task ID conflict and the known rejections
+// of the task are permanent. Everything else is retryable and counts toward the
+// breaker: network and pool errors, transient Redis replies, which may carry a
+// retry-after hint, and unknown replies like NOAUTH, NOPERM or an unknown
+// command, which a fix of Redis or the config resolves rather than of the row.
+func asynqError(err error) Result {
+	if goErrors.Is(err, asynq.ErrDuplicateTask) {
+		return Result{Response: "already enqueued: " + err.Error()}
+	}
+	if goErrors.Is(err, asynq.ErrTaskIDConflict) {
+		return Result{Err: Permanent(err)}
+	}
+
+	var reply redisReplyError
+	if goErrors.As(err, &reply) {
+		for _, transient := range transientRedisReplies {
+			if strings.HasPrefix(reply.Error(), transient.prefix) {
+				return Result{Err: Retryable(err, transient.retryAfter)}
+			}
+		}
+		return Result{Err: Retryable(err, 0)}
+	}
+
+	for _, rejection := range asynqTaskRejections {
+		if strings.Contains(err.Error(), rejection) {
+			return Result{Err: Permanent(err)}
+		}
+	}
+
+	return Result{Err: Retryable(err, 0)}
+}
@@ -0,0 +1,96 @@
+package destinations
+
+import (
+	"time"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// Breaker is the circuit breaker of one destination. It opens after threshold
+// consecutive failures and stays open for openTimeout, then lets a single
+// probe through: a success closes it again, a failure opens it for another
+// openTimeout. It is not safe for concurrent use, the Guard serializes it.
+type Breaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(threshold int, openTimeout time.Duration) *Breaker {
+	return &Breaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+	}
+}
+
+// Blocked reports whether a row can't go through at now and until when to
+// defer it. It does not take the probe, see Allow.
+func (b *Breaker) Blocked(now time.Time) (bool, time.Time) {
+	switch b.state {
+	case breakerOpen:
+		if retryAt := b.openedAt.Add(b.openTimeout); now.Before(retryAt) {
+			return true, retryAt
+		}
+		return false, time.Time{}
+	case breakerHalfOpen:
+		if b.probing {
+			return true, now.Add(probeWait)
+		}
+		return false, time.Time{}
+	default:
+		return false, time.Time{}
+	}
+}
+
+// probeWait defers the rows behind a half-open probe, the probe takes about one publish
+const probeWait = time.Second
+
+// Allow lets a row through, it must follow a Blocked that returned false. An
+// open breaker whose timeout passed turns half-open and the row is the probe.
+func (b *Breaker) Allow() {
+	if b.state == breakerOpen {
+		b.state = breakerHalfOpen
+	}
+	if b.state == breakerHalfOpen {
+		b.probing = true
+	}
+}
+
+// Success records a published row, it closes the breaker
+func (b *Breaker) Success() {
+	b.state = breakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records a failed row, a failed probe or the threshold-th consecutive failure opens the breaker
+func (b *Breaker) Failure(now time.Time) {
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		if b.state != breakerOpen {
+			b.openedAt = now
+		}
+		b.state = breakerOpen
+		b.probing = false
+	}
+}
+
+// Release gives back the probe of a row whose outcome says nothing about the destination
+func (b *Breaker) Release() {
+	b.probing = false
+}
+
+// Open reports whether the breaker is open
+func (b *Breaker) Open() bool {
+	return b.state == breakerOpen
+}
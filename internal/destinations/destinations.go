@@ -0,0 +1,118 @@
+package destinations
+
+import (
+	"context"
+	"fmt"
+
+	models "eventdrivensystem/internal/models/outbox"
+)
+
+// Result is the outcome of publishing one row. Response describes what the
+// destination answered, Err is nil when the row was published.
+type Result struct {
+	Response string
+	Err      error
+}
+
+// Publisher sends outbox rows to a destination in one batch. The returned
+// slice has a result per row in the same order, so a partial failure only
+// retries the rows that failed. An error is retried unless it is marked with
+// Permanent, see Retryable for hints.
+type Publisher interface {
+	PublishBatch(ctx context.Context, outboxes []models.Outbox) []Result
+}
+
+// Errors returns the error of every result
+func Errors(results []Result) []error {
+	errs := make([]error, len(results))
+	for i, result := range results {
+		errs[i] = result.Err
+	}
+	return errs
+}
+
+// Router hands every row to the publisher of its destination type
+type Router struct {
+	publishers map[string]Publisher
+}
+
+func NewRouter() *Router {
+	return &Router{publishers: map[string]Publisher{}}
+}
+
+// Register sets the publisher of a destination type, replacing any previous one
+func (r *Router) Register(destinationType string, p Publisher) {
+	r.publishers[destinationType] = p
+}
+
+// PublishBatch publishes the rows of each destination type as one batch, a
+// row without a registered destination fails without reaching any broker
+func (r *Router) PublishBatch(ctx context.Context, outboxes []models.Outbox) []Result {
+	results := make([]Result, len(outboxes))
+
+	indexes := map[string][]int{}
+	var order []string
+	for i, outbox := range outboxes {
+		if _, ok := indexes[outbox.DestinationType]; !ok {
+			order = append(order, outbox.DestinationType)
+		}
+		indexes[outbox.DestinationType] = append(indexes[outbox.DestinationType], i)
+	}
+
+	for _, destinationType := range order {
+		p, ok := r.publishers[destinationType]
+		if !ok {
+			for _, i := range indexes[destinationType] {
+				results[i].Err = Permanent(fmt.Errorf("unsupported destination type: %s", destinationType))
+			}
+			continue
+		}
+
+		batch := make([]models.Outbox, len(indexes[destinationType]))
+		for j, i := range indexes[destinationType] {
+			batch[j] = outboxes[i]
+		}
+
+		for j, result := range publishBatch(ctx, p, batch) {
+			results[indexes[destinationType][j]] = result
+		}
+	}
+
+	return results
+}
+
+// publishBatch turns a panic or a result of the wrong length into an error for every row
+func publishBatch(ctx context.Context, p Publisher, batch []models.Outbox) (results []Result) {
+	defer func() {
+		if r := recover(); r != nil {
+			results = failAll(len(batch), fmt.Errorf("panic: %v", r))
+		}
+	}()
+
+	results = p.PublishBatch(ctx, batch)
+	if len(results) != len(batch) {
+		return failAll(len(batch), fmt.Errorf("publisher returned %d results for %d rows", len(results), len(batch)))
+	}
+	return results
+}
+
+func failAll(n int, err error) []Result {
+	results := make([]Result, n)
+	for i := range results {
+		results[i].Err = err
+	}
+	return results
+}
+
+// Nop reports every row as published without sending it. It keeps the behaviour
+// the relay always had for a broker it has no client for, the response records
+// in the attempt history that the row was not published.
+type Nop struct{}
+
+func (Nop) PublishBatch(ctx context.Context, outboxes []models.Outbox) []Result {
+	results := make([]Result, len(outboxes))
+	for i := range results {
+		results[i].Response = "not published, no client is configured"
+	}
+	return results
+}
@@ -0,0 +1,279 @@
+package destinations_test
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"eventdrivensystem/internal/destinations"
+	models "eventdrivensystem/internal/models/outbox"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgtype"
+	"gotest.tools/assert"
+)
+
+func outboxRow(id, destinationType string, topic *string) models.Outbox {
+	return models.Outbox{
+		ID:              strfmt.UUID4(id),
+		EventType:       "email:send_notification",
+		DestinationType: destinationType,
+		Topic:           topic,
+		Payload:         &pgtype.JSONB{Bytes: []byte(`{"id":"` + id + `"}`), Status: pgtype.Present},
+	}
+}
+
+type publisherFunc func(ctx context.Context, outboxes []models.Outbox) []destinations.Result
+
+func (f publisherFunc) PublishBatch(ctx context.Context, outboxes []models.Outbox) []destinations.Result {
+	return f(ctx, outboxes)
+}
+
+func TestRouterMapsResultsBackToRows(t *testing.T) {
+	failed := goErrors.New("broker down")
+
+	router := destinations.NewRouter()
+	router.Register("A", publisherFunc(func(ctx context.Context, outboxes []models.Outbox) []destinations.Result {
+		assert.Equal(t, len(outboxes), 2)
+		return []destinations.Result{{Response: "ok"}, {Err: failed}}
+	}))
+	router.Register("B", publisherFunc(func(ctx context.Context, outboxes []models.Outbox) []destinations.Result {
+		panic("boom")
+	}))
+
+	results := router.PublishBatch(context.Background(), []models.Outbox{
+		outboxRow("1", "A", nil),
+		outboxRow("2", "B", nil),
+		outboxRow("3", "A", nil),
+		outboxRow("4", "C", nil),
+	})
+	errs := destinations.Errors(results)
+
+	assert.Equal(t, len(errs), 4)
+	assert.NilError(t, errs[0])
+	assert.Equal(t, results[0].Response, "ok")
+	assert.ErrorContains(t, errs[1], "panic: boom")
+	assert.Equal(t, errs[2], failed)
+	assert.ErrorContains(t, errs[3], "unsupported destination type: C")
+}
+
+type kafkaWriter struct {
+	msgs []destinations.KafkaMessage
+	err  error
+}
+
+func (w *kafkaWriter) WriteMessages(ctx context.Context, msgs ...destinations.KafkaMessage) error {
+	w.msgs = append(w.msgs, msgs...)
+	return w.err
+}
+
+func TestKafkaMapsPartialWriteErrors(t *testing.T) {
+	topic := "users"
+	failed := goErrors.New("leader not available")
+	writer := &kafkaWriter{err: destinations.KafkaWriteErrors{nil, failed}}
+
+	results := destinations.NewKafka(writer).PublishBatch(context.Background(), []models.Outbox{
+		outboxRow("1", models.OutboxDestinationTypeKafka, &topic),
+		outboxRow("2", models.OutboxDestinationTypeKafka, nil),
+		outboxRow("3", models.OutboxDestinationTypeKafka, &topic),
+	})
+
+	errs := destinations.Errors(results)
+	assert.Equal(t, len(writer.msgs), 2)
+	assert.Equal(t, string(writer.msgs[1].Value), `{"id":"3"}`)
+	assert.NilError(t, errs[0])
+	assert.Equal(t, results[0].Response, "produced to users")
+	assert.ErrorContains(t, errs[1], "has no kafka topic")
+	assert.Equal(t, errs[2], failed)
+}
+
+func TestKafkaFailsWholeBatchOnRequestError(t *testing.T) {
+	topic := "users"
+	failed := goErrors.New("connection refused")
+
+	errs := destinations.Errors(destinations.NewKafka(&kafkaWriter{err: failed}).PublishBatch(context.Background(), []models.Outbox{
+		outboxRow("1", models.OutboxDestinationTypeKafka, &topic),
+		outboxRow("2", models.OutboxDestinationTypeKafka, &topic),
+	}))
+
+	assert.Equal(t, errs[0], failed)
+	assert.Equal(t, errs[1], failed)
+}
+
+type confirmation struct {
+	acked bool
+	err   error
+}
+
+func (c confirmation) WaitContext(ctx context.Context) (bool, error) {
+	return c.acked, c.err
+}
+
+type amqpChannel struct {
+	published []string
+	results   map[string]confirmation
+	failing   map[string]error
+}
+
+func (c *amqpChannel) PublishWithDeferredConfirm(ctx context.Context, msg destinations.AMQPMessage) (destinations.AMQPConfirmation, error) {
+	if err := c.failing[msg.MessageID]; err != nil {
+		return nil, err
+	}
+	c.published = append(c.published, msg.MessageID)
+	return c.results[msg.MessageID], nil
+}
+
+func TestAMQPWaitsForEveryConfirm(t *testing.T) {
+	closed := goErrors.New("channel closed")
+	channel := &amqpChannel{
+		results: map[string]confirmation{
+			"1": {acked: true},
+			"2": {acked: false},
+		},
+		failing: map[string]error{"3": closed},
+	}
+
+	errs := destinations.Errors(destinations.NewAMQP(channel).PublishBatch(context.Background(), []models.Outbox{
+		outboxRow("1", models.OutboxDestinationTypeRabbitmq, nil),
+		outboxRow("2", models.OutboxDestinationTypeRabbitmq, nil),
+		outboxRow("3", models.OutboxDestinationTypeRabbitmq, nil),
+	}))
+
+	assert.DeepEqual(t, channel.published, []string{"1", "2"})
+	assert.NilError(t, errs[0])
+	assert.Equal(t, errs[1], destinations.ErrAMQPNacked)
+	assert.Equal(t, errs[2], closed)
+}
+
+func TestNopMarksRowsPublished(t *testing.T) {
+	rows := []models.Outbox{
+		outboxRow("1", models.OutboxDestinationTypeKafka, nil),
+		outboxRow("2", models.OutboxDestinationTypeRabbitmq, nil),
+	}
+
+	for _, result := range (destinations.Nop{}).PublishBatch(context.Background(), rows) {
+		assert.NilError(t, result.Err)
+		assert.Equal(t, result.Response, "not published, no client is configured")
+	}
+}
+
+type asynqClient struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+	err      error
+}
+
+func (c *asynqClient) EnqueueContext(ctx context.Context, task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inFlight--
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &asynq.TaskInfo{ID: "t", Queue: "default"}, nil
+}
+
+func TestAsynqBoundsConcurrentEnqueues(t *testing.T) {
+	client := &asynqClient{}
+	rows := make([]models.Outbox, 20)
+	for i := range rows {
+		rows[i] = outboxRow(fmt.Sprint(i), models.OutboxDestinationTypeAsynq, nil)
+	}
+
+	results := destinations.NewAsynq(client, 3, 4).PublishBatch(context.Background(), rows)
+
+	assert.Equal(t, len(results), 20)
+	for _, result := range results {
+		assert.NilError(t, result.Err)
+		assert.Equal(t, result.Response, "task t enqueued to default")
+	}
+	assert.Assert(t, client.peak <= 4)
+}
+
+type redisReply string
+
+func (e redisReply) Error() string { return string(e) }
+func (e redisReply) RedisError()   {}
+
+func TestAsynqClassifiesEnqueueErrors(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	testCases := []struct {
+		name       string
+		err        error
+		published  bool
+		permanent  bool
+		retryAfter time.Duration
+	}{
+		{name: "duplicate task", err: fmt.Errorf("%w", asynq.ErrDuplicateTask), published: true},
+		{name: "task id conflict", err: fmt.Errorf("%w", asynq.ErrTaskIDConflict), permanent: true},
+		{name: "malformed task", err: goErrors.New("cannot encode message: invalid payload"), permanent: true},
+		{name: "auth failure", err: fmt.Errorf("RDB.Enqueue: %w", redisReply("NOAUTH Authentication required."))},
+		{name: "wrong password", err: redisReply("WRONGPASS invalid username-password pair or user is disabled.")},
+		{name: "missing permission", err: redisReply("NOPERM this user has no permissions to run the 'evalsha' command")},
+		{name: "unknown command", err: redisReply("ERR unknown command 'evalsha'")},
+		{name: "unknown error", err: goErrors.New("something went wrong")},
+		{name: "redis loading", err: redisReply("LOADING Redis is loading the dataset in memory"), retryAfter: 5 * time.Second},
+		{name: "redis out of memory", err: redisReply("OOM command not allowed when used memory > 'maxmemory'"), retryAfter: 30 * time.Second},
+		{name: "connection refused", err: refused},
+		{name: "timeout", err: fmt.Errorf("enqueue: %w", context.DeadlineExceeded)},
+		{name: "pool timeout", err: goErrors.New("redis: connection pool timeout")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &asynqClient{err: tc.err}
+			result := destinations.NewAsynq(client, 3, 1).PublishBatch(context.Background(), []models.Outbox{
+				outboxRow("1", models.OutboxDestinationTypeAsynq, nil),
+			})[0]
+
+			if tc.published {
+				assert.NilError(t, result.Err)
+				assert.Assert(t, strings.HasPrefix(result.Response, "already enqueued"))
+				return
+			}
+
+			assert.Assert(t, goErrors.Is(result.Err, tc.err))
+			assert.Equal(t, destinations.IsPermanent(result.Err), tc.permanent)
+			retryAfter, _ := destinations.RetryAfter(result.Err)
+			assert.Equal(t, retryAfter, tc.retryAfter)
+		})
+	}
+}
+
+func TestAsynqUnknownRepliesOpenTheBreaker(t *testing.T) {
+	client := &asynqClient{err: redisReply("NOAUTH Authentication required.")}
+	guard := destinations.NewGuard(destinations.NewAsynq(client, 3, 1), destinations.GuardConfig{FailureThreshold: 2, OpenTimeout: time.Minute})
+
+	rows := []models.Outbox{outboxRow("1", models.OutboxDestinationTypeAsynq, nil)}
+	err := guard.PublishBatch(context.Background(), rows)[0].Err
+	assert.Assert(t, !destinations.IsPermanent(err))
+	assert.Assert(t, !isDeferred(err))
+
+	// the second failure opens the breaker, the row is deferred instead of failed
+	assert.Assert(t, isDeferred(guard.PublishBatch(context.Background(), rows)[0].Err))
+}
+
+func TestAsynqRejectsTasksWithoutType(t *testing.T) {
+	row := outboxRow("1", models.OutboxDestinationTypeAsynq, nil)
+	row.EventType = " "
+
+	result := destinations.NewAsynq(&asynqClient{}, 3, 1).PublishBatch(context.Background(), []models.Outbox{row})[0]
+	assert.Assert(t, destinations.IsPermanent(result.Err))
+	assert.ErrorContains(t, result.Err, "has no event type")
+}
@@ -0,0 +1,71 @@
+package destinations
+
+import (
+	goErrors "errors"
+	"time"
+)
+
+// PermanentError is a publish error that no retry can fix, like an unknown
+// destination or a message the broker rejects. The relay fails the row at once.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return "permanent: " + e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent marks err as permanent, it returns nil for a nil err
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// RetryableError is a publish error worth retrying. A positive RetryAfter is
+// the wait the destination asked for and replaces the backoff of the relay.
+type RetryableError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *RetryableError) Error() string {
+	if e.RetryAfter > 0 {
+		return e.Err.Error() + " (retry after " + e.RetryAfter.String() + ")"
+	}
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable marks err as retryable after retryAfter, 0 leaves the wait to the
+// relay. It returns nil for a nil err.
+func Retryable(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err, RetryAfter: retryAfter}
+}
+
+// IsPermanent reports whether err is marked permanent. An error that is not
+// marked either way is retryable.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return goErrors.As(err, &permanent)
+}
+
+// RetryAfter returns the retry-after hint of err, if it has one
+func RetryAfter(err error) (time.Duration, bool) {
+	var retryable *RetryableError
+	if goErrors.As(err, &retryable) && retryable.RetryAfter > 0 {
+		return retryable.RetryAfter, true
+	}
+	return 0, false
+}
@@ -0,0 +1,45 @@
+package destinations_test
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"eventdrivensystem/internal/destinations"
+	models "eventdrivensystem/internal/models/outbox"
+
+	"gotest.tools/assert"
+)
+
+func TestErrorClassification(t *testing.T) {
+	cause := goErrors.New("bad payload")
+
+	permanent := fmt.Errorf("publish: %w", destinations.Permanent(cause))
+	assert.Assert(t, destinations.IsPermanent(permanent))
+	assert.Assert(t, goErrors.Is(permanent, cause))
+	assert.Assert(t, !destinations.IsPermanent(cause))
+	assert.NilError(t, destinations.Permanent(nil))
+
+	retryAfter, ok := destinations.RetryAfter(destinations.Retryable(cause, 30*time.Second))
+	assert.Assert(t, ok)
+	assert.Equal(t, retryAfter, 30*time.Second)
+
+	_, ok = destinations.RetryAfter(destinations.Retryable(cause, 0))
+	assert.Assert(t, !ok)
+	assert.Assert(t, !destinations.IsPermanent(destinations.Retryable(cause, 0)))
+}
+
+func TestGuardDoesNotCountPermanentErrors(t *testing.T) {
+	next := &scriptedPublisher{err: destinations.Permanent(goErrors.New("message too large"))}
+	guard := destinations.NewGuard(next, destinations.GuardConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+	rows := []models.Outbox{outboxRow("1", models.OutboxDestinationTypeAsynq, nil)}
+	for i := 0; i < 3; i++ {
+		errs := destinations.Errors(guard.PublishBatch(context.Background(), rows))
+		assert.Assert(t, destinations.IsPermanent(errs[0]))
+		assert.Assert(t, !isDeferred(errs[0]))
+	}
+	assert.Equal(t, next.calls, 3)
+}
@@ -0,0 +1,189 @@
+package destinations
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	models "eventdrivensystem/internal/models/outbox"
+)
+
+// DeferredError is the result of a row that was held back instead of being
+// published. The relay reschedules it at Until without using up an attempt.
+type DeferredError struct {
+	Until  time.Time
+	Reason string
+	// Err is the publish error when the row was tried before being deferred
+	Err error
+}
+
+func (e *DeferredError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("deferred until %s, %s: %v", e.Until.Format(time.RFC3339), e.Reason, e.Err)
+	}
+	return fmt.Sprintf("deferred until %s, %s", e.Until.Format(time.RFC3339), e.Reason)
+}
+
+func (e *DeferredError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimit applies to the rows of a destination type or of an event type
+type RateLimit struct {
+	Destination string
+	EventType   string
+	PerSecond   float64
+	Burst       int
+}
+
+func (r RateLimit) matches(outbox models.Outbox) bool {
+	if r.Destination != "" {
+		return r.Destination == outbox.DestinationType
+	}
+	return r.EventType == outbox.EventType
+}
+
+type GuardConfig struct {
+	// FailureThreshold consecutive failures open the breaker of a destination for OpenTimeout
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	RateLimits       []RateLimit
+}
+
+type guardLimit struct {
+	RateLimit
+	bucket *TokenBucket
+}
+
+// Guard puts a circuit breaker per destination type and the configured token
+// buckets in front of a publisher. Rows it holds back get a *DeferredError.
+type Guard struct {
+	next Publisher
+	cfg  GuardConfig
+	now  func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+	limits   []*guardLimit
+}
+
+func NewGuard(next Publisher, cfg GuardConfig) *Guard {
+	g := &Guard{
+		next:     next,
+		cfg:      cfg,
+		now:      time.Now,
+		breakers: map[string]*Breaker{},
+	}
+	for _, limit := range cfg.RateLimits {
+		g.limits = append(g.limits, &guardLimit{
+			RateLimit: limit,
+			bucket:    NewTokenBucket(limit.PerSecond, limit.Burst),
+		})
+	}
+	return g
+}
+
+func (g *Guard) breaker(destinationType string) *Breaker {
+	b, ok := g.breakers[destinationType]
+	if !ok {
+		b = NewBreaker(g.cfg.FailureThreshold, g.cfg.OpenTimeout)
+		g.breakers[destinationType] = b
+	}
+	return b
+}
+
+// PublishBatch defers the rows of an open breaker or without a token and
+// publishes the others. Failed rows whose breaker is open afterwards are
+// deferred too, the destination failed rather than the row. Permanent errors
+// are the fault of the row, they neither count for the breaker nor are deferred.
+func (g *Guard) PublishBatch(ctx context.Context, outboxes []models.Outbox) []Result {
+	results := make([]Result, len(outboxes))
+	admitted := g.admit(outboxes, results)
+	if len(admitted) == 0 {
+		return results
+	}
+
+	batch := make([]models.Outbox, len(admitted))
+	for j, i := range admitted {
+		batch[j] = outboxes[i]
+	}
+	published := publishBatch(ctx, g.next, batch)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	for j, i := range admitted {
+		results[i] = published[j]
+
+		b := g.breaker(outboxes[i].DestinationType)
+		switch {
+		case published[j].Err == nil:
+			b.Success()
+		case IsPermanent(published[j].Err):
+			b.Release()
+		default:
+			b.Failure(now)
+		}
+	}
+
+	for _, i := range admitted {
+		err := results[i].Err
+		if err == nil || IsPermanent(err) {
+			continue
+		}
+		destinationType := outboxes[i].DestinationType
+		if blocked, until := g.breaker(destinationType).Blocked(now); blocked {
+			results[i].Err = &DeferredError{Until: until, Reason: "circuit breaker of " + destinationType + " is open", Err: err}
+		}
+	}
+
+	return results
+}
+
+// admit takes the breaker and rate limit decisions of every row, it sets the
+// error of the deferred rows and returns the indexes of the others
+func (g *Guard) admit(outboxes []models.Outbox, results []Result) []int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	var admitted []int
+	for i, outbox := range outboxes {
+		b := g.breaker(outbox.DestinationType)
+		if blocked, until := b.Blocked(now); blocked {
+			results[i].Err = &DeferredError{Until: until, Reason: "circuit breaker of " + outbox.DestinationType + " is open"}
+			continue
+		}
+
+		var (
+			limits  []*guardLimit
+			limited bool
+			until   time.Time
+		)
+		for _, limit := range g.limits {
+			if !limit.matches(outbox) {
+				continue
+			}
+			limits = append(limits, limit)
+			if ready, next := limit.bucket.Ready(now); !ready {
+				limited = true
+				if next.After(until) {
+					until = next
+				}
+			}
+		}
+		if limited {
+			results[i].Err = &DeferredError{Until: until, Reason: "rate limited"}
+			continue
+		}
+
+		for _, limit := range limits {
+			limit.bucket.Take()
+		}
+		b.Allow()
+		admitted = append(admitted, i)
+	}
+	return admitted
+}
@@ -0,0 +1,119 @@
+package destinations_test
+
+import (
+	"context"
+	goErrors "errors"
+	"testing"
+	"time"
+
+	"eventdrivensystem/internal/destinations"
+	models "eventdrivensystem/internal/models/outbox"
+
+	"gotest.tools/assert"
+)
+
+type scriptedPublisher struct {
+	calls int
+	err   error
+}
+
+func (p *scriptedPublisher) PublishBatch(ctx context.Context, outboxes []models.Outbox) []destinations.Result {
+	p.calls++
+	results := make([]destinations.Result, len(outboxes))
+	for i := range results {
+		results[i].Err = p.err
+	}
+	return results
+}
+
+func isDeferred(err error) bool {
+	var deferred *destinations.DeferredError
+	return goErrors.As(err, &deferred)
+}
+
+func TestGuardDefersRowsWhileBreakerIsOpen(t *testing.T) {
+	down := goErrors.New("redis down")
+	next := &scriptedPublisher{err: down}
+	guard := destinations.NewGuard(next, destinations.GuardConfig{FailureThreshold: 2, OpenTimeout: 50 * time.Millisecond})
+
+	rows := []models.Outbox{
+		outboxRow("1", models.OutboxDestinationTypeAsynq, nil),
+		outboxRow("2", models.OutboxDestinationTypeAsynq, nil),
+	}
+
+	// the failures that open the breaker are deferred too
+	errs := destinations.Errors(guard.PublishBatch(context.Background(), rows))
+	assert.Assert(t, isDeferred(errs[0]))
+	assert.Assert(t, isDeferred(errs[1]) && goErrors.Is(errs[1], down))
+
+	errs = destinations.Errors(guard.PublishBatch(context.Background(), rows))
+	assert.Equal(t, next.calls, 1)
+	assert.Assert(t, isDeferred(errs[0]) && isDeferred(errs[1]))
+
+	// after the timeout one row probes the destination
+	time.Sleep(60 * time.Millisecond)
+	next.err = nil
+	errs = destinations.Errors(guard.PublishBatch(context.Background(), rows))
+	assert.Equal(t, next.calls, 2)
+	assert.NilError(t, errs[0])
+	assert.Assert(t, isDeferred(errs[1]))
+
+	errs = destinations.Errors(guard.PublishBatch(context.Background(), rows))
+	assert.NilError(t, errs[0])
+	assert.NilError(t, errs[1])
+}
+
+func TestGuardKeepsFailuresBelowThreshold(t *testing.T) {
+	failed := goErrors.New("queue full")
+	guard := destinations.NewGuard(&scriptedPublisher{err: failed}, destinations.GuardConfig{FailureThreshold: 3, OpenTimeout: time.Minute})
+
+	errs := destinations.Errors(guard.PublishBatch(context.Background(), []models.Outbox{
+		outboxRow("1", models.OutboxDestinationTypeAsynq, nil),
+		outboxRow("2", models.OutboxDestinationTypeAsynq, nil),
+	}))
+	assert.Equal(t, errs[0], failed)
+	assert.Equal(t, errs[1], failed)
+}
+
+func TestGuardRateLimitsByEventType(t *testing.T) {
+	guard := destinations.NewGuard(&scriptedPublisher{}, destinations.GuardConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      time.Minute,
+		RateLimits:       []destinations.RateLimit{{EventType: "email:send_notification", PerSecond: 1, Burst: 2}},
+	})
+
+	other := outboxRow("4", models.OutboxDestinationTypeAsynq, nil)
+	other.EventType = "user:updated"
+
+	errs := destinations.Errors(guard.PublishBatch(context.Background(), []models.Outbox{
+		outboxRow("1", models.OutboxDestinationTypeAsynq, nil),
+		outboxRow("2", models.OutboxDestinationTypeAsynq, nil),
+		outboxRow("3", models.OutboxDestinationTypeAsynq, nil),
+		other,
+	}))
+
+	assert.NilError(t, errs[0])
+	assert.NilError(t, errs[1])
+	assert.ErrorContains(t, errs[2], "rate limited")
+	assert.NilError(t, errs[3])
+
+	var deferred *destinations.DeferredError
+	assert.Assert(t, goErrors.As(errs[2], &deferred))
+	assert.Assert(t, time.Until(deferred.Until) > 500*time.Millisecond)
+}
+
+func TestTokenBucketRefills(t *testing.T) {
+	now := time.Now()
+	bucket := destinations.NewTokenBucket(2, 1)
+
+	ready, _ := bucket.Ready(now)
+	assert.Assert(t, ready)
+	bucket.Take()
+
+	ready, next := bucket.Ready(now)
+	assert.Assert(t, !ready)
+	assert.Equal(t, next, now.Add(500*time.Millisecond))
+
+	ready, _ = bucket.Ready(now.Add(500 * time.Millisecond))
+	assert.Assert(t, ready)
+}
@@ -0,0 +1,106 @@
+package destinations
+
+import (
+	"context"
+	goErrors "errors"
+	"fmt"
+
+	models "eventdrivensystem/internal/models/outbox"
+)
+
+// KafkaMessage is one record of a produce request
+type KafkaMessage struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string][]byte
+}
+
+// KafkaWriteErrors reports the outcome of every message of a produce request in
+// order, a writer returns it when only some of the messages failed
+type KafkaWriteErrors []error
+
+func (e KafkaWriteErrors) Error() string {
+	failed := 0
+	for _, err := range e {
+		if err != nil {
+			failed++
+		}
+	}
+	return fmt.Sprintf("kafka write errors (%d/%d)", failed, len(e))
+}
+
+// KafkaWriter produces messages in a single batched request, like the Writer of
+// segmentio/kafka-go. An adapter marks the errors of the client, like a message
+// that is too large, with Permanent or Retryable.
+type KafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...KafkaMessage) error
+}
+
+// Kafka produces a batch as one request. The topic of the row is the Kafka
+// topic and its ordering key the message key, so related rows share a partition.
+type Kafka struct {
+	writer KafkaWriter
+}
+
+func NewKafka(writer KafkaWriter) *Kafka {
+	return &Kafka{writer: writer}
+}
+
+func (k *Kafka) PublishBatch(ctx context.Context, outboxes []models.Outbox) []Result {
+	results := make([]Result, len(outboxes))
+
+	var (
+		msgs    []KafkaMessage
+		indexes []int
+	)
+	for i, outbox := range outboxes {
+		if outbox.Topic == nil {
+			results[i].Err = Permanent(fmt.Errorf("outbox %s has no kafka topic", outbox.ID))
+			continue
+		}
+
+		msg := KafkaMessage{
+			Topic: *outbox.Topic,
+			Headers: map[string][]byte{
+				"event_type": []byte(outbox.EventType),
+				"outbox_id":  []byte(outbox.ID.String()),
+			},
+		}
+		if outbox.OrderingKey != nil {
+			msg.Key = []byte(*outbox.OrderingKey)
+		}
+		if outbox.Payload != nil {
+			msg.Value = outbox.Payload.Bytes
+		}
+
+		msgs = append(msgs, msg)
+		indexes = append(indexes, i)
+	}
+
+	if len(msgs) == 0 {
+		return results
+	}
+
+	err := k.writer.WriteMessages(ctx, msgs...)
+
+	var writeErrs KafkaWriteErrors
+	if goErrors.As(err, &writeErrs) && len(writeErrs) == len(msgs) {
+		for j, i := range indexes {
+			results[i] = kafkaResult(msgs[j], writeErrs[j])
+		}
+		return results
+	}
+
+	for j, i := range indexes {
+		results[i] = kafkaResult(msgs[j], err)
+	}
+	return results
+}
+
+func kafkaResult(msg KafkaMessage, err error) Result {
+	if err != nil {
+		return Result{Err: err}
+	}
+	return Result{Response: "produced to " + msg.Topic}
+}
@@ -0,0 +1,53 @@
+package destinations
+
+import (
+	"math"
+	"time"
+)
+
+// TokenBucket allows perSecond rows on average and bursts of up to burst rows.
+// It is not safe for concurrent use, the Guard serializes it.
+type TokenBucket struct {
+	perSecond float64
+	burst     float64
+
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a full bucket, a burst below 1 is perSecond rounded up
+func NewTokenBucket(perSecond float64, burst int) *TokenBucket {
+	b := float64(burst)
+	if burst < 1 {
+		b = math.Ceil(perSecond)
+	}
+	return &TokenBucket{
+		perSecond: perSecond,
+		burst:     b,
+		tokens:    b,
+	}
+}
+
+func (t *TokenBucket) refill(now time.Time) {
+	if !t.last.IsZero() && now.After(t.last) {
+		t.tokens = math.Min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.perSecond)
+	}
+	if now.After(t.last) {
+		t.last = now
+	}
+}
+
+// Ready reports whether a token is available at now, or when the next one will be
+func (t *TokenBucket) Ready(now time.Time) (bool, time.Time) {
+	t.refill(now)
+	if t.tokens >= 1 {
+		return true, time.Time{}
+	}
+	wait := time.Duration((1 - t.tokens) / t.perSecond * float64(time.Second))
+	return false, now.Add(wait)
+}
+
+// Take uses a token, it must follow a Ready that returned true
+func (t *TokenBucket) Take() {
+	t.tokens--
+}
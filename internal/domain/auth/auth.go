@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"eventdrivensystem/configs"
+	"eventdrivensystem/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type AuthDomain struct {
+	cfg *configs.AppConfig
+	db  *gorm.DB
+	log logger.Logger
+}
+
+type AuthDomainHandler interface {
+	AuthDomainReader
+	AuthDomainWriter
+}
+
+func NewAuthDomain(cfg *configs.AppConfig, log logger.Logger, db *gorm.DB) AuthDomainHandler {
+	return &AuthDomain{
+		cfg: cfg,
+		db:  db,
+		log: log,
+	}
+}
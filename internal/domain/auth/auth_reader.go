@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/auth"
+	"eventdrivensystem/pkg/util"
+)
+
+type AuthDomainReader interface {
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.RefreshToken, error)
+}
+
+func (u *AuthDomain) GetRefreshTokenByHash(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.RefreshToken, error) {
+	return u.getRefreshTokenByHashSql(ctx, tokenHash, opts...)
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/auth"
+	"eventdrivensystem/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+func (u *AuthDomain) getRefreshTokenByHashSql(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.RefreshToken, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+		rt  models.RefreshToken
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return &rt, db.Where("token_hash = ?", tokenHash).First(&rt).Error
+}
@@ -0,0 +1,35 @@
+package auth
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/auth"
+	"eventdrivensystem/pkg/util"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+type AuthDomainWriter interface {
+	CreateRefreshToken(ctx context.Context, rt *models.RefreshToken, opts ...util.DbOptions) (*models.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id strfmt.UUID4, revokedAt time.Time, opts ...util.DbOptions) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID strfmt.UUID4, revokedAt time.Time, opts ...util.DbOptions) error
+	RevokeUserRefreshTokens(ctx context.Context, userID strfmt.UUID4, revokedAt time.Time, opts ...util.DbOptions) error
+}
+
+func (u *AuthDomain) CreateRefreshToken(ctx context.Context, rt *models.RefreshToken, opts ...util.DbOptions) (*models.RefreshToken, error) {
+	return u.createRefreshTokenSql(ctx, rt, opts...)
+}
+
+func (u *AuthDomain) RevokeRefreshToken(ctx context.Context, id strfmt.UUID4, revokedAt time.Time, opts ...util.DbOptions) error {
+	return u.revokeRefreshTokensSql(ctx, "id = ?", id, revokedAt, opts...)
+}
+
+// RevokeRefreshTokenFamily revokes every token rotated from the same login
+func (u *AuthDomain) RevokeRefreshTokenFamily(ctx context.Context, familyID strfmt.UUID4, revokedAt time.Time, opts ...util.DbOptions) error {
+	return u.revokeRefreshTokensSql(ctx, "family_id = ?", familyID, revokedAt, opts...)
+}
+
+// RevokeUserRefreshTokens revokes every session of a user
+func (u *AuthDomain) RevokeUserRefreshTokens(ctx context.Context, userID strfmt.UUID4, revokedAt time.Time, opts ...util.DbOptions) error {
+	return u.revokeRefreshTokensSql(ctx, "user_id = ?", userID, revokedAt, opts...)
+}
@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/auth"
+	"eventdrivensystem/pkg/util"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (u *AuthDomain) createRefreshTokenSql(ctx context.Context, rt *models.RefreshToken, opts ...util.DbOptions) (*models.RefreshToken, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return rt, db.Create(rt).Error
+}
+
+func (u *AuthDomain) revokeRefreshTokensSql(ctx context.Context, query string, arg interface{}, revokedAt time.Time, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(&models.RefreshToken{}).
+		Where(query, arg).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", revokedAt).Error
+}
@@ -2,30 +2,57 @@ package domain
 
 import (
 	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/domain/auth"
 	"eventdrivensystem/internal/domain/notification"
 	"eventdrivensystem/internal/domain/outbox"
+	sagaDomain "eventdrivensystem/internal/domain/saga"
+	"eventdrivensystem/internal/domain/scheduler"
 	"eventdrivensystem/internal/domain/user"
+	"eventdrivensystem/internal/events"
+	"eventdrivensystem/internal/saga"
+	"eventdrivensystem/pkg/databases"
 	"eventdrivensystem/pkg/logger"
 
 	"gorm.io/gorm"
 )
 
 type Domain struct {
+	UnitOfWork   databases.UnitOfWork
+	Events       events.Publisher
+	Routes       *events.Registry
+	Sagas        saga.Orchestrator
 	User         user.UserDomainHandler
+	Auth         auth.AuthDomainHandler
 	Outbox       outbox.OutboxDomainHandler
 	Notification notification.NotificationDomainHandler
+	Scheduler    scheduler.SchedulerDomainHandler
+	Saga         sagaDomain.SagaDomainHandler
 }
 
 func NewDomain(cfg *configs.AppConfig,
 	db *gorm.DB,
 	log logger.Logger) *Domain {
+	unitOfWork := databases.NewUnitOfWork(db)
+	outboxDomain := outbox.NewOutboxDomain(cfg, log, db)
+	sagaDom := sagaDomain.NewSagaDomain(cfg, log, db)
+
+	eventRegistry := events.DefaultRegistry()
+	publisher := events.NewPublisher(eventRegistry, outboxDomain)
+
 	return &Domain{
-		User:   user.NewUserDomain(cfg, log, db),
-		Outbox: outbox.NewOutboxDomain(cfg, log, db),
+		UnitOfWork: unitOfWork,
+		Events:     publisher,
+		Routes:     eventRegistry,
+		Sagas:      saga.NewOrchestrator(saga.DefaultRegistry(), eventRegistry, unitOfWork, publisher, sagaDom),
+		User:       user.NewUserDomain(cfg, log, db),
+		Auth:       auth.NewAuthDomain(cfg, log, db),
+		Outbox:     outboxDomain,
 		Notification: notification.NewNotificationDomain(
 			cfg,
 			log,
 			db,
 		),
+		Scheduler: scheduler.NewSchedulerDomain(cfg, log, db),
+		Saga:      sagaDom,
 	}
 }
@@ -1,7 +1,6 @@
 package notification
 
 import (
-	"context"
 	"eventdrivensystem/configs"
 	"eventdrivensystem/pkg/logger"
 
@@ -15,7 +14,6 @@ type NotificationDomain struct {
 }
 
 type NotificationDomainHandler interface {
-	BeginTx(ctx context.Context) *gorm.DB
 	NotificationDomainWriter
 }
 
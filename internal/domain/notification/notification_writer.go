@@ -4,8 +4,6 @@ import (
 	"context"
 	models "eventdrivensystem/internal/models/notification"
 	"eventdrivensystem/pkg/util"
-
-	"gorm.io/gorm"
 )
 
 type NotificationDomainWriter interface {
@@ -15,7 +13,3 @@ type NotificationDomainWriter interface {
 func (u *NotificationDomain) CreateNotification(ctx context.Context, p *models.Notification, opts ...util.DbOptions) (*models.Notification, error) {
 	return u.createNotificationSql(ctx, p, opts...)
 }
-
-func (u *NotificationDomain) BeginTx(ctx context.Context) *gorm.DB {
-	return u.db.Begin()
-}
@@ -14,6 +14,7 @@ type OutboxDomain struct {
 }
 
 type OutboxDomainHandler interface {
+	OutboxDomainReader
 	OutboxDomainWriter
 }
 
@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/util"
+	"time"
+)
+
+type OutboxDomainReader interface {
+	GetPendingOutboxByDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) (*models.Outbox, error)
+	ListScheduledOutbox(ctx context.Context, filter *models.ScheduledOutboxFilter, now time.Time, opts ...util.DbOptions) ([]models.Outbox, int64, error)
+	ListOutboxAttempts(ctx context.Context, filter *models.OutboxAttemptFilter, opts ...util.DbOptions) ([]models.OutboxAttempt, int64, error)
+}
+
+func (u *OutboxDomain) GetPendingOutboxByDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) (*models.Outbox, error) {
+	return u.getPendingOutboxByDedupKeySql(ctx, dedupKey, opts...)
+}
+
+// ListScheduledOutbox returns pending rows that execute after now, soonest first
+func (u *OutboxDomain) ListScheduledOutbox(ctx context.Context, filter *models.ScheduledOutboxFilter, now time.Time, opts ...util.DbOptions) ([]models.Outbox, int64, error) {
+	return u.listScheduledOutboxSql(ctx, filter, now, opts...)
+}
+
+// ListOutboxAttempts returns the attempts of an outbox row, oldest first
+func (u *OutboxDomain) ListOutboxAttempts(ctx context.Context, filter *models.OutboxAttemptFilter, opts ...util.DbOptions) ([]models.OutboxAttempt, int64, error) {
+	return u.listOutboxAttemptsSql(ctx, filter, opts...)
+}
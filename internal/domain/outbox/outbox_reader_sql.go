@@ -0,0 +1,94 @@
+package outbox
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/util"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+func (u *OutboxDomain) getPendingOutboxByDedupKeySql(ctx context.Context, dedupKey string, opts ...util.DbOptions) (*models.Outbox, error) {
+	var (
+		db     *gorm.DB
+		opt    util.DbOptions
+		outbox models.Outbox
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// outbox reads decide what gets published, a lagging replica could publish twice
+	db = opt.Extract(ctx, u.db).Clauses(dbresolver.Write)
+
+	err := db.Where("dedup_key = ? AND status = ?", dedupKey, models.OutboxStatusPending).
+		First(&outbox).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &outbox, nil
+}
+
+func (u *OutboxDomain) listScheduledOutboxSql(ctx context.Context, filter *models.ScheduledOutboxFilter, now time.Time, opts ...util.DbOptions) ([]models.Outbox, int64, error) {
+	var (
+		db       *gorm.DB
+		opt      util.DbOptions
+		outboxes []models.Outbox
+		total    int64
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db).Clauses(dbresolver.Write)
+
+	q := db.Model(&models.Outbox{}).Where("status = ? AND execute_at > ?", models.OutboxStatusPending, now)
+	if filter.EventType != "" {
+		q = q.Where("event_type = ?", filter.EventType)
+	}
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("execute_at asc").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&outboxes).Error
+
+	return outboxes, total, err
+}
+
+func (u *OutboxDomain) listOutboxAttemptsSql(ctx context.Context, filter *models.OutboxAttemptFilter, opts ...util.DbOptions) ([]models.OutboxAttempt, int64, error) {
+	var (
+		db       *gorm.DB
+		opt      util.DbOptions
+		attempts []models.OutboxAttempt
+		total    int64
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// the history is only read by admins, a replica is fine
+	db = opt.Extract(ctx, u.db)
+
+	q := db.Model(&models.OutboxAttempt{}).Where("outbox_id = ?", filter.OutboxID)
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("started_at asc, attempt asc").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&attempts).Error
+
+	return attempts, total, err
+}
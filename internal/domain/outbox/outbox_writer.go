@@ -4,13 +4,77 @@ import (
 	"context"
 	models "eventdrivensystem/internal/models/outbox"
 	"eventdrivensystem/pkg/util"
+	"time"
 )
 
 type OutboxDomainWriter interface {
 	CreateOutbox(ctx context.Context, Outbox *models.Outbox, opts ...util.DbOptions) error
+	LockDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) error
+	CancelOutboxByDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) (int64, error)
+	UpdateOutboxResults(ctx context.Context, results []models.OutboxResult, opts ...util.DbOptions) error
+	RequeueStaleOutboxes(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error)
+	CreateOutboxAttempts(ctx context.Context, attempts []models.OutboxAttempt, opts ...util.DbOptions) error
+	DeleteOutboxAttemptsBefore(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error)
 }
 
 func (u *OutboxDomain) CreateOutbox(ctx context.Context, outbox *models.Outbox, opts ...util.DbOptions) error {
 	outbox.Status = models.OutboxStatusPending
 	return u.createOutboxSql(ctx, outbox, opts...)
 }
+
+// LockDedupKey serializes writers of the same dedup key until the transaction ends.
+// The outbox is partitioned by execute_at, so a unique index cannot enforce the key.
+func (u *OutboxDomain) LockDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) error {
+	return u.lockDedupKeySql(ctx, dedupKey, opts...)
+}
+
+// CancelOutboxByDedupKey cancels the pending rows of the key and returns how many were cancelled.
+// Rows the relay already picked are no longer pending and are left untouched.
+func (u *OutboxDomain) CancelOutboxByDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) (int64, error) {
+	return u.cancelOutboxByDedupKeySql(ctx, dedupKey, opts...)
+}
+
+// UpdateOutboxResults writes relay results back with one UPDATE per status and
+// chunk of maxResultsPerStatement rows
+func (u *OutboxDomain) UpdateOutboxResults(ctx context.Context, results []models.OutboxResult, opts ...util.DbOptions) error {
+	byStatus := map[string][]models.OutboxResult{}
+	var statuses []string
+	for _, result := range results {
+		if _, ok := byStatus[result.Status]; !ok {
+			statuses = append(statuses, result.Status)
+		}
+		byStatus[result.Status] = append(byStatus[result.Status], result)
+	}
+
+	for _, status := range statuses {
+		group := byStatus[status]
+		for start := 0; start < len(group); start += maxResultsPerStatement {
+			end := min(start+maxResultsPerStatement, len(group))
+			if err := u.updateOutboxResultsSql(ctx, status, group[start:end], opts...); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RequeueStaleOutboxes moves up to limit rows that are PROCESSING since before
+// back to RETRYING, their result was lost with a failed write or a crashed
+// relay. It returns how many it moved, call it until it returns less than limit.
+func (u *OutboxDomain) RequeueStaleOutboxes(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error) {
+	return u.requeueStaleOutboxesSql(ctx, before, limit, opts...)
+}
+
+func (u *OutboxDomain) CreateOutboxAttempts(ctx context.Context, attempts []models.OutboxAttempt, opts ...util.DbOptions) error {
+	if len(attempts) == 0 {
+		return nil
+	}
+	return u.createOutboxAttemptsSql(ctx, attempts, opts...)
+}
+
+// DeleteOutboxAttemptsBefore deletes up to limit attempts that finished before
+// before and returns how many it deleted, call it until it returns less than limit
+func (u *OutboxDomain) DeleteOutboxAttemptsBefore(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error) {
+	return u.deleteOutboxAttemptsBeforeSql(ctx, before, limit, opts...)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	models "eventdrivensystem/internal/models/outbox"
 	"eventdrivensystem/pkg/util"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -22,3 +24,146 @@ func (u *OutboxDomain) createOutboxSql(ctx context.Context, outbox *models.Outbo
 
 	return db.Create(outbox).Error
 }
+
+func (u *OutboxDomain) lockDedupKeySql(ctx context.Context, dedupKey string, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "outbox_dedup:"+dedupKey).Error
+}
+
+func (u *OutboxDomain) cancelOutboxByDedupKeySql(ctx context.Context, dedupKey string, opts ...util.DbOptions) (int64, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	result := db.Model(&models.Outbox{}).
+		Where("dedup_key = ? AND status = ?", dedupKey, models.OutboxStatusPending).
+		Update("status", models.OutboxStatusCancelled)
+
+	return result.RowsAffected, result.Error
+}
+
+// maxResultsPerStatement keeps an update well below the 65535 bind parameters of Postgres
+const maxResultsPerStatement = 1000
+
+// updateOutboxResultsSql joins the rows to a VALUES list, a NULL column keeps the
+// current value. Only rows still holding the lease of their result are updated.
+func (u *OutboxDomain) updateOutboxResultsSql(ctx context.Context, status string, results []models.OutboxResult, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	values := make([]string, len(results))
+	args := make([]interface{}, 0, 2+7*len(results))
+	args = append(args, status)
+	for i, result := range results {
+		refund := 0
+		if result.RefundAttempt {
+			refund = 1
+		}
+		values[i] = "(?::uuid, ?::timestamptz, ?::timestamptz, ?::timestamptz, ?::timestamptz, ?::text, ?::int)"
+		args = append(args, result.ID, result.ExecuteAt, result.ProcessingStartedAt, result.SentAt, result.NextExecuteAt, result.ErrorMessage, refund)
+	}
+	args = append(args, models.OutboxStatusProcessing)
+
+	return db.Exec(`
+		UPDATE outbox SET
+			status = ?,
+			sent_at = COALESCE(v.sent_at, outbox.sent_at),
+			execute_at = COALESCE(v.next_execute_at, outbox.execute_at),
+			error_message = COALESCE(v.error_message, outbox.error_message),
+			attempt = outbox.attempt - v.refund_attempt,
+			processing_started_at = NULL
+		FROM (VALUES `+strings.Join(values, ", ")+`) AS v(id, execute_at, processing_started_at, sent_at, next_execute_at, error_message, refund_attempt)
+		WHERE outbox.id = v.id AND outbox.execute_at = v.execute_at
+		AND outbox.status = ? AND outbox.processing_started_at = v.processing_started_at
+	`, args...).Error
+}
+
+func (u *OutboxDomain) requeueStaleOutboxesSql(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	// SKIP LOCKED leaves the rows another relay is requeueing to it
+	result := db.Exec(`
+		UPDATE outbox SET
+			status = ?,
+			processing_started_at = NULL,
+			error_message = ?
+		WHERE (id, execute_at) IN (
+			SELECT id, execute_at FROM outbox
+			WHERE status = ? AND processing_started_at < ?
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+	`, models.OutboxStatusRetrying, "processing lease expired", models.OutboxStatusProcessing, before, limit)
+
+	return result.RowsAffected, result.Error
+}
+
+func (u *OutboxDomain) createOutboxAttemptsSql(ctx context.Context, attempts []models.OutboxAttempt, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.CreateInBatches(attempts, maxResultsPerStatement).Error
+}
+
+func (u *OutboxDomain) deleteOutboxAttemptsBeforeSql(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	// deleting in chunks keeps every statement and its locks short
+	result := db.Exec(`
+		DELETE FROM outbox_attempts
+		WHERE id IN (SELECT id FROM outbox_attempts WHERE finished_at < ? LIMIT ?)
+	`, before, limit)
+
+	return result.RowsAffected, result.Error
+}
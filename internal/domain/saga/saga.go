@@ -0,0 +1,27 @@
+package saga
+
+import (
+	"eventdrivensystem/configs"
+	"eventdrivensystem/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type SagaDomain struct {
+	cfg *configs.AppConfig
+	db  *gorm.DB
+	log logger.Logger
+}
+
+type SagaDomainHandler interface {
+	SagaDomainReader
+	SagaDomainWriter
+}
+
+func NewSagaDomain(cfg *configs.AppConfig, log logger.Logger, db *gorm.DB) SagaDomainHandler {
+	return &SagaDomain{
+		cfg: cfg,
+		db:  db,
+		log: log,
+	}
+}
@@ -0,0 +1,23 @@
+package saga
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/saga"
+	"eventdrivensystem/pkg/util"
+
+	"github.com/go-openapi/strfmt"
+)
+
+type SagaDomainReader interface {
+	GetSagaByID(ctx context.Context, id strfmt.UUID4, opts ...util.DbOptions) (*models.Saga, error)
+	ListSagaSteps(ctx context.Context, sagaID strfmt.UUID4, opts ...util.DbOptions) ([]models.SagaStep, error)
+}
+
+func (u *SagaDomain) GetSagaByID(ctx context.Context, id strfmt.UUID4, opts ...util.DbOptions) (*models.Saga, error) {
+	return u.getSagaByIDSql(ctx, id, opts...)
+}
+
+// ListSagaSteps returns the steps of the saga ordered by step index
+func (u *SagaDomain) ListSagaSteps(ctx context.Context, sagaID strfmt.UUID4, opts ...util.DbOptions) ([]models.SagaStep, error) {
+	return u.listSagaStepsSql(ctx, sagaID, opts...)
+}
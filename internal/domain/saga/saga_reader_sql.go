@@ -0,0 +1,51 @@
+package saga
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/saga"
+	"eventdrivensystem/pkg/util"
+
+	"github.com/go-openapi/strfmt"
+	"gorm.io/gorm"
+)
+
+func (u *SagaDomain) getSagaByIDSql(ctx context.Context, id strfmt.UUID4, opts ...util.DbOptions) (*models.Saga, error) {
+	var (
+		db   *gorm.DB
+		opt  util.DbOptions
+		saga models.Saga
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	err := db.Where("id = ?", id).First(&saga).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &saga, nil
+}
+
+func (u *SagaDomain) listSagaStepsSql(ctx context.Context, sagaID strfmt.UUID4, opts ...util.DbOptions) ([]models.SagaStep, error) {
+	var (
+		db    *gorm.DB
+		opt   util.DbOptions
+		steps []models.SagaStep
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	err := db.Where("saga_id = ?", sagaID).
+		Order("step_index asc").
+		Find(&steps).Error
+
+	return steps, err
+}
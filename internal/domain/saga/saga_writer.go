@@ -0,0 +1,26 @@
+package saga
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/saga"
+	"eventdrivensystem/pkg/util"
+)
+
+type SagaDomainWriter interface {
+	CreateSaga(ctx context.Context, saga *models.Saga, steps []models.SagaStep, opts ...util.DbOptions) error
+	UpdateSaga(ctx context.Context, saga *models.Saga, opts ...util.DbOptions) error
+	UpdateSagaStep(ctx context.Context, step *models.SagaStep, opts ...util.DbOptions) error
+}
+
+// CreateSaga inserts the saga and its steps, the steps get the id of the saga
+func (u *SagaDomain) CreateSaga(ctx context.Context, saga *models.Saga, steps []models.SagaStep, opts ...util.DbOptions) error {
+	return u.createSagaSql(ctx, saga, steps, opts...)
+}
+
+func (u *SagaDomain) UpdateSaga(ctx context.Context, saga *models.Saga, opts ...util.DbOptions) error {
+	return u.updateSagaSql(ctx, saga, opts...)
+}
+
+func (u *SagaDomain) UpdateSagaStep(ctx context.Context, step *models.SagaStep, opts ...util.DbOptions) error {
+	return u.updateSagaStepSql(ctx, step, opts...)
+}
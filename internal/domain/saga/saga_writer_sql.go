@@ -0,0 +1,70 @@
+package saga
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/saga"
+	"eventdrivensystem/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+func (u *SagaDomain) createSagaSql(ctx context.Context, saga *models.Saga, steps []models.SagaStep, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	if err := db.Create(saga).Error; err != nil {
+		return err
+	}
+
+	if len(steps) == 0 {
+		return nil
+	}
+
+	for i := range steps {
+		steps[i].SagaID = saga.ID
+	}
+
+	return db.Create(&steps).Error
+}
+
+func (u *SagaDomain) updateSagaSql(ctx context.Context, saga *models.Saga, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(saga).
+		Select("status", "current_step", "data", "error_message", "updated_at").
+		Updates(saga).Error
+}
+
+func (u *SagaDomain) updateSagaStepSql(ctx context.Context, step *models.SagaStep, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(step).
+		Select("status", "error_message", "started_at", "deadline_at", "finished_at", "updated_at").
+		Updates(step).Error
+}
@@ -0,0 +1,27 @@
+package scheduler
+
+import (
+	"eventdrivensystem/configs"
+	"eventdrivensystem/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+type SchedulerDomain struct {
+	cfg *configs.AppConfig
+	db  *gorm.DB
+	log logger.Logger
+}
+
+type SchedulerDomainHandler interface {
+	SchedulerDomainReader
+	SchedulerDomainWriter
+}
+
+func NewSchedulerDomain(cfg *configs.AppConfig, log logger.Logger, db *gorm.DB) SchedulerDomainHandler {
+	return &SchedulerDomain{
+		cfg: cfg,
+		db:  db,
+		log: log,
+	}
+}
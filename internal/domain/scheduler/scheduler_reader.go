@@ -0,0 +1,16 @@
+package scheduler
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/scheduler"
+	"eventdrivensystem/pkg/util"
+)
+
+type SchedulerDomainReader interface {
+	GetLatestRun(ctx context.Context, jobName string, opts ...util.DbOptions) (*models.SchedulerRun, error)
+}
+
+// GetLatestRun returns the run with the latest scheduled time, fired or skipped
+func (u *SchedulerDomain) GetLatestRun(ctx context.Context, jobName string, opts ...util.DbOptions) (*models.SchedulerRun, error) {
+	return u.getLatestRunSql(ctx, jobName, opts...)
+}
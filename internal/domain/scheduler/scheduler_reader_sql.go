@@ -0,0 +1,32 @@
+package scheduler
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/scheduler"
+	"eventdrivensystem/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+func (u *SchedulerDomain) getLatestRunSql(ctx context.Context, jobName string, opts ...util.DbOptions) (*models.SchedulerRun, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+		run models.SchedulerRun
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	err := db.Where("job_name = ?", jobName).
+		Order("scheduled_at desc").
+		First(&run).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
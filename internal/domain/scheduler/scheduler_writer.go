@@ -0,0 +1,22 @@
+package scheduler
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/scheduler"
+	"eventdrivensystem/pkg/util"
+)
+
+type SchedulerDomainWriter interface {
+	TryLock(ctx context.Context, name string, opts ...util.DbOptions) (bool, error)
+	CreateRun(ctx context.Context, run *models.SchedulerRun, opts ...util.DbOptions) error
+}
+
+// TryLock takes a transaction scoped advisory lock without waiting, it must run
+// inside a transaction and reports false when another session holds the lock
+func (u *SchedulerDomain) TryLock(ctx context.Context, name string, opts ...util.DbOptions) (bool, error) {
+	return u.tryLockSql(ctx, name, opts...)
+}
+
+func (u *SchedulerDomain) CreateRun(ctx context.Context, run *models.SchedulerRun, opts ...util.DbOptions) error {
+	return u.createRunSql(ctx, run, opts...)
+}
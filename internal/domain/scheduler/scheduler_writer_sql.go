@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/scheduler"
+	"eventdrivensystem/pkg/util"
+
+	"gorm.io/gorm"
+)
+
+func (u *SchedulerDomain) tryLockSql(ctx context.Context, name string, opts ...util.DbOptions) (bool, error) {
+	var (
+		db       *gorm.DB
+		opt      util.DbOptions
+		acquired bool
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	err := db.Raw("SELECT pg_try_advisory_xact_lock(hashtext(?))", name).Scan(&acquired).Error
+
+	return acquired, err
+}
+
+func (u *SchedulerDomain) createRunSql(ctx context.Context, run *models.SchedulerRun, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Create(run).Error
+}
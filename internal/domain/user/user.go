@@ -1,7 +1,6 @@
 package user
 
 import (
-	"context"
 	"eventdrivensystem/configs"
 	"eventdrivensystem/pkg/logger"
 
@@ -15,7 +14,7 @@ type UserDomain struct {
 }
 
 type UserDomainHandler interface {
-	BeginTx(ctx context.Context) *gorm.DB
+	UserDomainReader
 	UserDomainWriter
 }
 
@@ -0,0 +1,49 @@
+package user
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/user"
+	"eventdrivensystem/pkg/util"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+type UserDomainReader interface {
+	GetUserByID(ctx context.Context, id strfmt.UUID4, opts ...util.DbOptions) (*models.User, error)
+	GetUserByEmail(ctx context.Context, email string, opts ...util.DbOptions) (*models.User, error)
+	ListUsers(ctx context.Context, filter *models.UserFilter, opts ...util.DbOptions) ([]models.User, int64, error)
+	GetVerificationTokenByHash(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.EmailVerificationToken, error)
+	CountVerificationTokensSince(ctx context.Context, userID strfmt.UUID4, since time.Time, opts ...util.DbOptions) (int64, *time.Time, error)
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.PasswordResetToken, error)
+	GetLatestPasswordResetToken(ctx context.Context, userID strfmt.UUID4, opts ...util.DbOptions) (*models.PasswordResetToken, error)
+}
+
+func (u *UserDomain) GetUserByID(ctx context.Context, id strfmt.UUID4, opts ...util.DbOptions) (*models.User, error) {
+	return u.getUserByIDSql(ctx, id, opts...)
+}
+
+func (u *UserDomain) GetUserByEmail(ctx context.Context, email string, opts ...util.DbOptions) (*models.User, error) {
+	return u.getUserByEmailSql(ctx, email, opts...)
+}
+
+func (u *UserDomain) ListUsers(ctx context.Context, filter *models.UserFilter, opts ...util.DbOptions) ([]models.User, int64, error) {
+	return u.listUsersSql(ctx, filter, opts...)
+}
+
+func (u *UserDomain) GetVerificationTokenByHash(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.EmailVerificationToken, error) {
+	return u.getVerificationTokenByHashSql(ctx, tokenHash, opts...)
+}
+
+// CountVerificationTokensSince returns how many tokens were issued to the user since the given time and when the latest one was issued
+func (u *UserDomain) CountVerificationTokensSince(ctx context.Context, userID strfmt.UUID4, since time.Time, opts ...util.DbOptions) (int64, *time.Time, error) {
+	return u.countVerificationTokensSinceSql(ctx, userID, since, opts...)
+}
+
+func (u *UserDomain) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.PasswordResetToken, error) {
+	return u.getPasswordResetTokenByHashSql(ctx, tokenHash, opts...)
+}
+
+func (u *UserDomain) GetLatestPasswordResetToken(ctx context.Context, userID strfmt.UUID4, opts ...util.DbOptions) (*models.PasswordResetToken, error) {
+	return u.getLatestPasswordResetTokenSql(ctx, userID, opts...)
+}
@@ -0,0 +1,153 @@
+package user
+
+import (
+	"context"
+	models "eventdrivensystem/internal/models/user"
+	"eventdrivensystem/pkg/util"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"gorm.io/gorm"
+)
+
+func (u *UserDomain) getUserByIDSql(ctx context.Context, id strfmt.UUID4, opts ...util.DbOptions) (*models.User, error) {
+	var (
+		db   *gorm.DB
+		opt  util.DbOptions
+		user models.User
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return &user, db.Where("id = ? AND deleted_at IS NULL", id).First(&user).Error
+}
+
+func (u *UserDomain) getUserByEmailSql(ctx context.Context, email string, opts ...util.DbOptions) (*models.User, error) {
+	var (
+		db   *gorm.DB
+		opt  util.DbOptions
+		user models.User
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return &user, db.Where("email = ? AND deleted_at IS NULL", email).First(&user).Error
+}
+
+func (u *UserDomain) listUsersSql(ctx context.Context, filter *models.UserFilter, opts ...util.DbOptions) ([]models.User, int64, error) {
+	var (
+		db    *gorm.DB
+		opt   util.DbOptions
+		users []models.User
+		total int64
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	q := db.Model(&models.User{}).Where("deleted_at IS NULL")
+	if filter.Email != "" {
+		q = q.Where("email ILIKE ?", "%"+filter.Email+"%")
+	}
+	if filter.Verified != nil {
+		if *filter.Verified {
+			q = q.Where("verified_at IS NOT NULL")
+		} else {
+			q = q.Where("verified_at IS NULL")
+		}
+	}
+
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := q.Order("created_at desc").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&users).Error
+
+	return users, total, err
+}
+
+func (u *UserDomain) getVerificationTokenByHashSql(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.EmailVerificationToken, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+		vt  models.EmailVerificationToken
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return &vt, db.Where("token_hash = ?", tokenHash).First(&vt).Error
+}
+
+func (u *UserDomain) countVerificationTokensSinceSql(ctx context.Context, userID strfmt.UUID4, since time.Time, opts ...util.DbOptions) (int64, *time.Time, error) {
+	var (
+		db     *gorm.DB
+		opt    util.DbOptions
+		result struct {
+			Total  int64
+			Latest *time.Time
+		}
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	err := db.Model(&models.EmailVerificationToken{}).
+		Select("COUNT(*) AS total, MAX(created_at) AS latest").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Scan(&result).Error
+
+	return result.Total, result.Latest, err
+}
+
+func (u *UserDomain) getPasswordResetTokenByHashSql(ctx context.Context, tokenHash string, opts ...util.DbOptions) (*models.PasswordResetToken, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+		rt  models.PasswordResetToken
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return &rt, db.Where("token_hash = ?", tokenHash).First(&rt).Error
+}
+
+func (u *UserDomain) getLatestPasswordResetTokenSql(ctx context.Context, userID strfmt.UUID4, opts ...util.DbOptions) (*models.PasswordResetToken, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+		rt  models.PasswordResetToken
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return &rt, db.Where("user_id = ?", userID).Order("created_at desc").First(&rt).Error
+}
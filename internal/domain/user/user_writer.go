@@ -4,18 +4,76 @@ import (
 	"context"
 	models "eventdrivensystem/internal/models/user"
 	"eventdrivensystem/pkg/util"
+	"time"
 
-	"gorm.io/gorm"
+	"github.com/go-openapi/strfmt"
 )
 
 type UserDomainWriter interface {
 	CreateUser(ctx context.Context, user *models.User, opts ...util.DbOptions) (*models.User, error)
+	UpdateUserProfile(ctx context.Context, user *models.User, opts ...util.DbOptions) error
+	UpdateUserEmail(ctx context.Context, id strfmt.UUID4, email string, opts ...util.DbOptions) error
+	SoftDeleteUser(ctx context.Context, id strfmt.UUID4, deletedAt time.Time, opts ...util.DbOptions) error
+	MarkUserVerified(ctx context.Context, id strfmt.UUID4, verifiedAt time.Time, opts ...util.DbOptions) error
+	CreateVerificationToken(ctx context.Context, vt *models.EmailVerificationToken, opts ...util.DbOptions) (*models.EmailVerificationToken, error)
+	ConsumeVerificationTokens(ctx context.Context, userID strfmt.UUID4, usedAt time.Time, opts ...util.DbOptions) error
+	RotateVerificationToken(ctx context.Context, id strfmt.UUID4, tokenHash string, now time.Time, opts ...util.DbOptions) error
+	UpdateUserPassword(ctx context.Context, id strfmt.UUID4, hashedPassword string, opts ...util.DbOptions) error
+	CreatePasswordResetToken(ctx context.Context, rt *models.PasswordResetToken, opts ...util.DbOptions) (*models.PasswordResetToken, error)
+	ConsumePasswordResetTokens(ctx context.Context, userID strfmt.UUID4, usedAt time.Time, opts ...util.DbOptions) error
+	RotatePasswordResetToken(ctx context.Context, id strfmt.UUID4, tokenHash string, now time.Time, opts ...util.DbOptions) error
 }
 
 func (u *UserDomain) CreateUser(ctx context.Context, user *models.User, opts ...util.DbOptions) (*models.User, error) {
 	return u.createUserSql(ctx, user, opts...)
 }
 
-func (u *UserDomain) BeginTx(ctx context.Context) *gorm.DB {
-	return u.db.Begin()
+func (u *UserDomain) UpdateUserProfile(ctx context.Context, user *models.User, opts ...util.DbOptions) error {
+	return u.updateUserProfileSql(ctx, user, opts...)
+}
+
+// UpdateUserEmail changes the email and clears verified_at, the new address has to be verified again
+func (u *UserDomain) UpdateUserEmail(ctx context.Context, id strfmt.UUID4, email string, opts ...util.DbOptions) error {
+	return u.updateUserEmailSql(ctx, id, email, opts...)
+}
+
+// SoftDeleteUser sets deleted_at, returns gorm.ErrRecordNotFound when the user is missing or already deleted
+func (u *UserDomain) SoftDeleteUser(ctx context.Context, id strfmt.UUID4, deletedAt time.Time, opts ...util.DbOptions) error {
+	return u.softDeleteUserSql(ctx, id, deletedAt, opts...)
+}
+
+func (u *UserDomain) MarkUserVerified(ctx context.Context, id strfmt.UUID4, verifiedAt time.Time, opts ...util.DbOptions) error {
+	return u.markUserVerifiedSql(ctx, id, verifiedAt, opts...)
+}
+
+func (u *UserDomain) CreateVerificationToken(ctx context.Context, vt *models.EmailVerificationToken, opts ...util.DbOptions) (*models.EmailVerificationToken, error) {
+	return u.createVerificationTokenSql(ctx, vt, opts...)
+}
+
+// ConsumeVerificationTokens marks every outstanding token of the user as used
+func (u *UserDomain) ConsumeVerificationTokens(ctx context.Context, userID strfmt.UUID4, usedAt time.Time, opts ...util.DbOptions) error {
+	return u.consumeVerificationTokensSql(ctx, userID, usedAt, opts...)
+}
+
+// RotateVerificationToken replaces the hash of a usable token, returns gorm.ErrRecordNotFound when the token is used or expired
+func (u *UserDomain) RotateVerificationToken(ctx context.Context, id strfmt.UUID4, tokenHash string, now time.Time, opts ...util.DbOptions) error {
+	return u.rotateVerificationTokenSql(ctx, id, tokenHash, now, opts...)
+}
+
+func (u *UserDomain) UpdateUserPassword(ctx context.Context, id strfmt.UUID4, hashedPassword string, opts ...util.DbOptions) error {
+	return u.updateUserPasswordSql(ctx, id, hashedPassword, opts...)
+}
+
+func (u *UserDomain) CreatePasswordResetToken(ctx context.Context, rt *models.PasswordResetToken, opts ...util.DbOptions) (*models.PasswordResetToken, error) {
+	return u.createPasswordResetTokenSql(ctx, rt, opts...)
+}
+
+// ConsumePasswordResetTokens marks every outstanding reset token of the user as used
+func (u *UserDomain) ConsumePasswordResetTokens(ctx context.Context, userID strfmt.UUID4, usedAt time.Time, opts ...util.DbOptions) error {
+	return u.consumePasswordResetTokensSql(ctx, userID, usedAt, opts...)
+}
+
+// RotatePasswordResetToken replaces the hash of a usable reset token, returns gorm.ErrRecordNotFound when the token is used or expired
+func (u *UserDomain) RotatePasswordResetToken(ctx context.Context, id strfmt.UUID4, tokenHash string, now time.Time, opts ...util.DbOptions) error {
+	return u.rotatePasswordResetTokenSql(ctx, id, tokenHash, now, opts...)
 }
@@ -4,7 +4,9 @@ import (
 	"context"
 	models "eventdrivensystem/internal/models/user"
 	"eventdrivensystem/pkg/util"
+	"time"
 
+	"github.com/go-openapi/strfmt"
 	"gorm.io/gorm"
 )
 
@@ -22,3 +24,215 @@ func (u *UserDomain) createUserSql(ctx context.Context, user *models.User, opts
 
 	return user, db.Create(user).Error
 }
+
+func (u *UserDomain) updateUserProfileSql(ctx context.Context, user *models.User, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(user).
+		Where("deleted_at IS NULL").
+		Select(models.FieldFullName, models.FieldPhoneNumber, "updated_at").
+		Updates(user).Error
+}
+
+func (u *UserDomain) updateUserEmailSql(ctx context.Context, id strfmt.UUID4, email string, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]interface{}{
+			models.FieldEmail: email,
+			"verified_at":     nil,
+			"updated_at":      time.Now(),
+		}).Error
+}
+
+func (u *UserDomain) softDeleteUserSql(ctx context.Context, id strfmt.UUID4, deletedAt time.Time, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	result := db.Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", deletedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (u *UserDomain) markUserVerifiedSql(ctx context.Context, id strfmt.UUID4, verifiedAt time.Time, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(&models.User{}).
+		Where("id = ? AND verified_at IS NULL", id).
+		Update("verified_at", verifiedAt).Error
+}
+
+func (u *UserDomain) createVerificationTokenSql(ctx context.Context, vt *models.EmailVerificationToken, opts ...util.DbOptions) (*models.EmailVerificationToken, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return vt, db.Create(vt).Error
+}
+
+func (u *UserDomain) consumeVerificationTokensSql(ctx context.Context, userID strfmt.UUID4, usedAt time.Time, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", usedAt).Error
+}
+
+func (u *UserDomain) rotateVerificationTokenSql(ctx context.Context, id strfmt.UUID4, tokenHash string, now time.Time, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	result := db.Model(&models.EmailVerificationToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("token_hash", tokenHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (u *UserDomain) updateUserPasswordSql(ctx context.Context, id strfmt.UUID4, hashedPassword string, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(&models.User{}).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("password", hashedPassword).Error
+}
+
+func (u *UserDomain) createPasswordResetTokenSql(ctx context.Context, rt *models.PasswordResetToken, opts ...util.DbOptions) (*models.PasswordResetToken, error) {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return rt, db.Create(rt).Error
+}
+
+func (u *UserDomain) consumePasswordResetTokensSql(ctx context.Context, userID strfmt.UUID4, usedAt time.Time, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	return db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", usedAt).Error
+}
+
+func (u *UserDomain) rotatePasswordResetTokenSql(ctx context.Context, id strfmt.UUID4, tokenHash string, now time.Time, opts ...util.DbOptions) error {
+	var (
+		db  *gorm.DB
+		opt util.DbOptions
+	)
+
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	db = opt.Extract(ctx, u.db)
+
+	result := db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("token_hash", tokenHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
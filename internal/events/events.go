@@ -0,0 +1,82 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event is a typed fact that happened in the domain. EventType is stored as
+// outbox.event_type and doubles as the asynq task type.
+type Event interface {
+	EventType() string
+}
+
+// Route describes where and when an event is delivered
+type Route struct {
+	// Destination is one of the outbox destination types, e.g. ASYNQ or KAFKA
+	Destination string
+	// Topic is the broker topic or asynq queue, empty uses the destination default
+	Topic string
+	// Delay postpones delivery relative to the publish time
+	Delay time.Duration
+	// OrderingKey, when set, makes the relay deliver events sharing a key in order
+	OrderingKey func(event Event) string
+}
+
+// OrderingKeyOf adapts a typed key function to Route.OrderingKey
+func OrderingKeyOf[E Event](fn func(E) string) func(event Event) string {
+	return func(event Event) string {
+		if e, ok := event.(E); ok {
+			return fn(e)
+		}
+		return ""
+	}
+}
+
+type Registry struct {
+	mu     sync.RWMutex
+	routes map[string]Route
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		routes: map[string]Route{},
+	}
+}
+
+// Register binds an event type to its route, registering the same type twice panics
+func (r *Registry) Register(event Event, route Route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.routes[event.EventType()]; ok {
+		panic(fmt.Sprintf("events: %s registered twice", event.EventType()))
+	}
+	r.routes[event.EventType()] = route
+}
+
+func (r *Registry) Route(eventType string) (Route, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	route, ok := r.routes[eventType]
+	return route, ok
+}
+
+// Topics returns the distinct topics of the routes to destination, sorted. Routes
+// without a topic use the destination default and are left out.
+func (r *Registry) Topics(destination string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var topics []string
+	for _, route := range r.routes {
+		if route.Destination == destination && route.Topic != "" && !slices.Contains(topics, route.Topic) {
+			topics = append(topics, route.Topic)
+		}
+	}
+	slices.Sort(topics)
+	return topics
+}
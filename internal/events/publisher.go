@@ -0,0 +1,170 @@
+package events
+
+import (
+	"context"
+	goErrors "errors"
+	"eventdrivensystem/internal/domain/outbox"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/databases"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"gorm.io/gorm"
+)
+
+type Publisher interface {
+	// Publish serializes the event and writes it to the outbox inside tx, so it
+	// is delivered if and only if the transaction commits
+	Publish(ctx context.Context, tx databases.Tx, event Event, opts ...PublishOption) error
+	// Cancel cancels the pending event published with dedupKey, it reports false
+	// when there is nothing left to cancel because the event already fired
+	Cancel(ctx context.Context, tx databases.Tx, dedupKey string) (bool, error)
+}
+
+type PublishOption func(*publishOptions)
+
+type publishOptions struct {
+	delay       *time.Duration
+	executeAt   *time.Time
+	orderingKey *string
+	dedupKey    *string
+	topic       *string
+}
+
+// WithDelay overrides the delay of the route
+func WithDelay(d time.Duration) PublishOption {
+	return func(o *publishOptions) {
+		o.delay = &d
+	}
+}
+
+// WithExecuteAt schedules the event at t, it takes precedence over any delay
+func WithExecuteAt(t time.Time) PublishOption {
+	return func(o *publishOptions) {
+		o.executeAt = &t
+	}
+}
+
+// WithDedupKey makes the publish idempotent: while an event with the same key
+// is still pending the new one is dropped. The key is also the handle for Cancel.
+func WithDedupKey(key string) PublishOption {
+	return func(o *publishOptions) {
+		o.dedupKey = &key
+	}
+}
+
+// WithOrderingKey overrides the ordering key of the route
+func WithOrderingKey(key string) PublishOption {
+	return func(o *publishOptions) {
+		o.orderingKey = &key
+	}
+}
+
+// WithTopic overrides the topic of the route, for asynq it is the queue
+func WithTopic(topic string) PublishOption {
+	return func(o *publishOptions) {
+		o.topic = &topic
+	}
+}
+
+type publisher struct {
+	registry     *Registry
+	outboxDomain outbox.OutboxDomainHandler
+}
+
+func NewPublisher(registry *Registry, outboxDomain outbox.OutboxDomainHandler) Publisher {
+	return &publisher{
+		registry:     registry,
+		outboxDomain: outboxDomain,
+	}
+}
+
+func (p *publisher) Publish(ctx context.Context, tx databases.Tx, event Event, opts ...PublishOption) error {
+	route, ok := p.registry.Route(event.EventType())
+	if !ok {
+		return fmt.Errorf("events: no route registered for %s", event.EventType())
+	}
+
+	o := publishOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	payload := &pgtype.JSONB{}
+	if err := payload.Set(event); err != nil {
+		return fmt.Errorf("events: serialize %s: %w", event.EventType(), err)
+	}
+
+	delay := route.Delay
+	if o.delay != nil {
+		delay = *o.delay
+	}
+
+	executeAt := time.Now().Add(delay)
+	if o.executeAt != nil {
+		executeAt = *o.executeAt
+	}
+
+	row := outboxModels.Outbox{
+		Payload:         payload,
+		EventType:       event.EventType(),
+		DestinationType: route.Destination,
+		ExecuteAt:       executeAt,
+		DedupKey:        o.dedupKey,
+	}
+
+	if o.topic != nil {
+		row.Topic = o.topic
+	} else if route.Topic != "" {
+		row.Topic = &route.Topic
+	}
+
+	if o.orderingKey != nil {
+		row.OrderingKey = o.orderingKey
+	} else if route.OrderingKey != nil {
+		if key := route.OrderingKey(event); key != "" {
+			row.OrderingKey = &key
+		}
+	}
+
+	if o.dedupKey != nil {
+		pending, err := p.isPending(ctx, tx, *o.dedupKey)
+		if err != nil || pending {
+			return err
+		}
+	}
+
+	return p.outboxDomain.CreateOutbox(ctx, &row, tx.DbOptions())
+}
+
+func (p *publisher) Cancel(ctx context.Context, tx databases.Tx, dedupKey string) (bool, error) {
+	if err := p.outboxDomain.LockDedupKey(ctx, dedupKey, tx.DbOptions()); err != nil {
+		return false, err
+	}
+
+	cancelled, err := p.outboxDomain.CancelOutboxByDedupKey(ctx, dedupKey, tx.DbOptions())
+	if err != nil {
+		return false, err
+	}
+
+	return cancelled > 0, nil
+}
+
+// isPending locks the dedup key for the rest of tx and reports whether an event
+// with the key is still waiting to be relayed
+func (p *publisher) isPending(ctx context.Context, tx databases.Tx, dedupKey string) (bool, error) {
+	if err := p.outboxDomain.LockDedupKey(ctx, dedupKey, tx.DbOptions()); err != nil {
+		return false, err
+	}
+
+	_, err := p.outboxDomain.GetPendingOutboxByDedupKey(ctx, dedupKey, tx.DbOptions())
+	if err != nil {
+		if goErrors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,191 @@
+package events_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"eventdrivensystem/internal/events"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/util"
+
+	"gorm.io/gorm"
+	"gotest.tools/assert"
+)
+
+type fakeOutboxDomain struct {
+	rows []*outboxModels.Outbox
+}
+
+func (f *fakeOutboxDomain) CreateOutbox(ctx context.Context, outbox *outboxModels.Outbox, opts ...util.DbOptions) error {
+	outbox.Status = outboxModels.OutboxStatusPending
+	f.rows = append(f.rows, outbox)
+	return nil
+}
+
+func (f *fakeOutboxDomain) GetPendingOutboxByDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) (*outboxModels.Outbox, error) {
+	for _, row := range f.rows {
+		if row.DedupKey != nil && *row.DedupKey == dedupKey && row.Status == outboxModels.OutboxStatusPending {
+			return row, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeOutboxDomain) ListScheduledOutbox(ctx context.Context, filter *outboxModels.ScheduledOutboxFilter, now time.Time, opts ...util.DbOptions) ([]outboxModels.Outbox, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeOutboxDomain) ListOutboxAttempts(ctx context.Context, filter *outboxModels.OutboxAttemptFilter, opts ...util.DbOptions) ([]outboxModels.OutboxAttempt, int64, error) {
+	return nil, 0, nil
+}
+
+func (f *fakeOutboxDomain) LockDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) error {
+	return nil
+}
+
+func (f *fakeOutboxDomain) CancelOutboxByDedupKey(ctx context.Context, dedupKey string, opts ...util.DbOptions) (int64, error) {
+	var cancelled int64
+	for _, row := range f.rows {
+		if row.DedupKey != nil && *row.DedupKey == dedupKey && row.Status == outboxModels.OutboxStatusPending {
+			row.Status = outboxModels.OutboxStatusCancelled
+			cancelled++
+		}
+	}
+	return cancelled, nil
+}
+
+func (f *fakeOutboxDomain) UpdateOutboxResults(ctx context.Context, results []outboxModels.OutboxResult, opts ...util.DbOptions) error {
+	return nil
+}
+
+func (f *fakeOutboxDomain) RequeueStaleOutboxes(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeOutboxDomain) CreateOutboxAttempts(ctx context.Context, attempts []outboxModels.OutboxAttempt, opts ...util.DbOptions) error {
+	return nil
+}
+
+func (f *fakeOutboxDomain) DeleteOutboxAttemptsBefore(ctx context.Context, before time.Time, limit int, opts ...util.DbOptions) (int64, error) {
+	return 0, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) DB() *gorm.DB                             { return nil }
+func (fakeTx) DbOptions() util.DbOptions                { return util.DbOptions{} }
+func (fakeTx) Context() context.Context                 { return context.Background() }
+func (fakeTx) AfterCommit(fn func(ctx context.Context)) {}
+
+type accountOpened struct {
+	AccountID string `json:"account_id"`
+}
+
+func (e *accountOpened) EventType() string { return "account.opened" }
+
+func TestPublish(t *testing.T) {
+	registry := events.NewRegistry()
+	registry.Register(&accountOpened{}, events.Route{
+		Destination: outboxModels.OutboxDestinationTypeKafka,
+		Topic:       "accounts",
+		Delay:       time.Minute,
+		OrderingKey: events.OrderingKeyOf(func(e *accountOpened) string { return e.AccountID }),
+	})
+
+	outboxDomain := &fakeOutboxDomain{}
+	publisher := events.NewPublisher(registry, outboxDomain)
+
+	before := time.Now()
+	err := publisher.Publish(context.Background(), fakeTx{}, &accountOpened{AccountID: "acc-1"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(outboxDomain.rows), 1)
+
+	row := outboxDomain.rows[0]
+	assert.Equal(t, row.EventType, "account.opened")
+	assert.Equal(t, row.DestinationType, outboxModels.OutboxDestinationTypeKafka)
+	assert.Equal(t, *row.Topic, "accounts")
+	assert.Equal(t, *row.OrderingKey, "acc-1")
+	assert.Assert(t, !row.ExecuteAt.Before(before.Add(time.Minute)))
+
+	var payload accountOpened
+	assert.NilError(t, json.Unmarshal(row.Payload.Bytes, &payload))
+	assert.Equal(t, payload.AccountID, "acc-1")
+}
+
+func TestPublishOptionsOverrideRoute(t *testing.T) {
+	registry := events.NewRegistry()
+	registry.Register(&accountOpened{}, events.Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+		Delay:       time.Hour,
+	})
+
+	outboxDomain := &fakeOutboxDomain{}
+	publisher := events.NewPublisher(registry, outboxDomain)
+
+	err := publisher.Publish(context.Background(), fakeTx{}, &accountOpened{AccountID: "acc-2"},
+		events.WithDelay(0),
+		events.WithOrderingKey("custom"),
+		events.WithTopic("bulk"),
+	)
+	assert.NilError(t, err)
+
+	row := outboxDomain.rows[0]
+	assert.Equal(t, *row.Topic, "bulk")
+	assert.Equal(t, *row.OrderingKey, "custom")
+	assert.Assert(t, row.ExecuteAt.Before(time.Now().Add(time.Minute)))
+}
+
+func TestPublishUnregisteredEvent(t *testing.T) {
+	publisher := events.NewPublisher(events.NewRegistry(), &fakeOutboxDomain{})
+
+	err := publisher.Publish(context.Background(), fakeTx{}, &accountOpened{})
+	assert.ErrorContains(t, err, "no route registered for account.opened")
+}
+
+func TestPublishWithExecuteAt(t *testing.T) {
+	registry := events.NewRegistry()
+	registry.Register(&accountOpened{}, events.Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+		Delay:       time.Minute,
+	})
+
+	outboxDomain := &fakeOutboxDomain{}
+	publisher := events.NewPublisher(registry, outboxDomain)
+
+	at := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := publisher.Publish(context.Background(), fakeTx{}, &accountOpened{}, events.WithExecuteAt(at), events.WithDelay(time.Hour))
+	assert.NilError(t, err)
+	assert.Equal(t, outboxDomain.rows[0].ExecuteAt, at)
+}
+
+func TestPublishDedupAndCancel(t *testing.T) {
+	registry := events.NewRegistry()
+	registry.Register(&accountOpened{}, events.Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+	})
+
+	outboxDomain := &fakeOutboxDomain{}
+	publisher := events.NewPublisher(registry, outboxDomain)
+	ctx := context.Background()
+
+	// a second publish with a pending key is dropped
+	assert.NilError(t, publisher.Publish(ctx, fakeTx{}, &accountOpened{}, events.WithDedupKey("tip:acc-1")))
+	assert.NilError(t, publisher.Publish(ctx, fakeTx{}, &accountOpened{}, events.WithDedupKey("tip:acc-1")))
+	assert.Equal(t, len(outboxDomain.rows), 1)
+	assert.Equal(t, *outboxDomain.rows[0].DedupKey, "tip:acc-1")
+
+	cancelled, err := publisher.Cancel(ctx, fakeTx{}, "tip:acc-1")
+	assert.NilError(t, err)
+	assert.Assert(t, cancelled)
+	assert.Equal(t, outboxDomain.rows[0].Status, outboxModels.OutboxStatusCancelled)
+
+	cancelled, err = publisher.Cancel(ctx, fakeTx{}, "tip:acc-1")
+	assert.NilError(t, err)
+	assert.Assert(t, !cancelled)
+
+	// once cancelled the key can be scheduled again
+	assert.NilError(t, publisher.Publish(ctx, fakeTx{}, &accountOpened{}, events.WithDedupKey("tip:acc-1")))
+	assert.Equal(t, len(outboxDomain.rows), 2)
+}
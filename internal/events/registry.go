@@ -0,0 +1,45 @@
+package events
+
+import (
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+)
+
+// DefaultRegistry routes every event the application publishes
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register(&asynqModels.AsynqSendNotificationPayload{}, Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+	})
+	r.Register(&asynqModels.AsynqSendVerificationPayload{}, Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+	})
+	r.Register(&asynqModels.AsynqSendPasswordResetPayload{}, Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+	})
+	r.Register(&asynqModels.AsynqSendOnboardingTipPayload{}, Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+	})
+	r.Register(&asynqModels.AsynqUserUpdatedPayload{}, Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+		OrderingKey: OrderingKeyOf(func(e *asynqModels.AsynqUserUpdatedPayload) string {
+			return e.UserID.String()
+		}),
+	})
+	r.Register(&asynqModels.AsynqUserDeletedPayload{}, Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+		OrderingKey: OrderingKeyOf(func(e *asynqModels.AsynqUserDeletedPayload) string {
+			return e.UserID.String()
+		}),
+	})
+
+	r.Register(&asynqModels.AsynqSagaReplyPayload{}, Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+	})
+	r.Register(&asynqModels.AsynqSagaTimeoutPayload{}, Route{
+		Destination: outboxModels.OutboxDestinationTypeAsynq,
+	})
+
+	return r
+}
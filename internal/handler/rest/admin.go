@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"eventdrivensystem/internal/generated/api_models"
+	"eventdrivensystem/internal/handler/rest/mapper"
+	"eventdrivensystem/pkg/errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/labstack/echo/v4"
+)
+
+func (r *RouterHandler) RegisterAdminRoutes(base *echo.Group) {
+	v1 := base.Group("/v1/admin", r.Authenticate, r.RequireAdmin)
+	{
+		v1.GET("/scheduled-events", r.ListScheduledEvents)
+		v1.DELETE("/scheduled-events/:dedup_key", r.CancelScheduledEvent)
+		v1.GET("/outbox/:id/attempts", r.ListOutboxAttempts)
+	}
+}
+
+func (r *RouterHandler) ListScheduledEvents(c echo.Context) error {
+	var (
+		param = mapper.ToListScheduledOutboxParam(c.QueryParam("event_type"))
+		err   error
+	)
+
+	err = echo.QueryParamsBinder(c).
+		Int("page", &param.Page).
+		Int("page_size", &param.PageSize).
+		BindError()
+	if err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	result, err := r.uc.Outbox.ListScheduledEvents(c.Request().Context(), param)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, mapper.ToScheduledEventListResponse(result))
+}
+
+func (r *RouterHandler) CancelScheduledEvent(c echo.Context) error {
+	var (
+		resp api_models.PlainResponse
+		err  error
+	)
+
+	// dedup keys usually contain ':' which clients may percent-encode
+	dedupKey, err := url.PathUnescape(c.Param("dedup_key"))
+	if err != nil || dedupKey == "" {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = r.uc.Outbox.CancelScheduledEvent(c.Request().Context(), mapper.ToCancelScheduledOutboxParam(dedupKey))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	resp.Success = true
+	resp.Message = "Scheduled event cancelled"
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *RouterHandler) ListOutboxAttempts(c echo.Context) error {
+	var (
+		param = mapper.ToListOutboxAttemptsParam(c.Param("id"))
+		err   error
+	)
+
+	if !strfmt.IsUUID(param.OutboxID) {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = echo.QueryParamsBinder(c).
+		Int("page", &param.Page).
+		Int("page_size", &param.PageSize).
+		BindError()
+	if err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	result, err := r.uc.Outbox.ListOutboxAttempts(c.Request().Context(), param)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, mapper.ToOutboxAttemptListResponse(result))
+}
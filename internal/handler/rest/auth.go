@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"eventdrivensystem/internal/generated/api_models"
+	"eventdrivensystem/internal/handler/rest/mapper"
+	"eventdrivensystem/pkg/errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (r *RouterHandler) RegisterAuthRoutes(base *echo.Group) {
+	v1 := base.Group("/v1/auth")
+	{
+		v1.POST("/login", r.Login)
+		v1.POST("/refresh", r.RefreshToken)
+		v1.POST("/logout", r.Logout, r.Authenticate)
+		v1.POST("/password-reset", r.RequestPasswordReset)
+		v1.POST("/password-reset/confirm", r.ConfirmPasswordReset)
+	}
+}
+
+func (r *RouterHandler) Login(c echo.Context) error {
+	var (
+		req api_models.LoginRequest
+		err error
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = r.validator.Struct(&req)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	result, err := r.uc.Auth.Login(c.Request().Context(), mapper.ToLoginParam(&req))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, mapper.ToTokenResponse(result))
+}
+
+func (r *RouterHandler) RefreshToken(c echo.Context) error {
+	var (
+		req api_models.RefreshTokenRequest
+		err error
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = r.validator.Struct(&req)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	result, err := r.uc.Auth.RefreshToken(c.Request().Context(), mapper.ToRefreshTokenParam(&req))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, mapper.ToTokenResponse(result))
+}
+
+func (r *RouterHandler) Logout(c echo.Context) error {
+	var (
+		req  api_models.RefreshTokenRequest
+		resp api_models.PlainResponse
+		err  error
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = r.validator.Struct(&req)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	err = r.uc.Auth.Logout(c.Request().Context(), mapper.ToLogoutParam(getUserID(c), &req))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	resp.Success = true
+	resp.Message = "Logged out successfully"
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *RouterHandler) RequestPasswordReset(c echo.Context) error {
+	var (
+		req  api_models.PasswordResetRequest
+		resp api_models.PlainResponse
+		err  error
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = r.validator.Struct(&req)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	err = r.uc.User.RequestPasswordReset(c.Request().Context(), mapper.ToRequestPasswordResetParam(&req))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	resp.Success = true
+	resp.Message = "If the email is registered, a password reset link has been sent"
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *RouterHandler) ConfirmPasswordReset(c echo.Context) error {
+	var (
+		req  api_models.PasswordResetConfirmRequest
+		resp api_models.PlainResponse
+		err  error
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = r.validator.Struct(&req)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	err = r.uc.User.ConfirmPasswordReset(c.Request().Context(), mapper.ToConfirmPasswordResetParam(&req))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	resp.Success = true
+	resp.Message = "Password has been reset"
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"eventdrivensystem/internal/generated/api_models"
+	models "eventdrivensystem/internal/models/auth"
+	"time"
+)
+
+func ToLoginParam(request *api_models.LoginRequest) *models.LoginParam {
+	return &models.LoginParam{
+		Email:    request.Email,
+		Password: request.Password,
+	}
+}
+
+func ToRefreshTokenParam(request *api_models.RefreshTokenRequest) *models.RefreshTokenParam {
+	return &models.RefreshTokenParam{
+		RefreshToken: request.RefreshToken,
+	}
+}
+
+func ToLogoutParam(userID string, request *api_models.RefreshTokenRequest) *models.LogoutParam {
+	return &models.LogoutParam{
+		UserID:       userID,
+		RefreshToken: request.RefreshToken,
+	}
+}
+
+func ToTokenResponse(result *models.TokenResult) *api_models.TokenResponse {
+	return &api_models.TokenResponse{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		TokenType:    result.TokenType,
+		ExpiresIn:    int64(time.Until(result.AccessTokenExpiresAt).Seconds()),
+	}
+}
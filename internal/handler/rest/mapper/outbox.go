@@ -0,0 +1,91 @@
+package mapper
+
+import (
+	"encoding/json"
+	"eventdrivensystem/internal/generated/api_models"
+	models "eventdrivensystem/internal/models/outbox"
+
+	"github.com/go-openapi/strfmt"
+)
+
+func ToListScheduledOutboxParam(eventType string) *models.ListScheduledOutboxParam {
+	return &models.ListScheduledOutboxParam{
+		EventType: eventType,
+	}
+}
+
+func ToCancelScheduledOutboxParam(dedupKey string) *models.CancelScheduledOutboxParam {
+	return &models.CancelScheduledOutboxParam{
+		DedupKey: dedupKey,
+	}
+}
+
+func ToScheduledEventResponse(outbox *models.Outbox) *api_models.ScheduledEventResponse {
+	resp := &api_models.ScheduledEventResponse{
+		ID:              strfmt.UUID(outbox.ID),
+		EventType:       outbox.EventType,
+		DestinationType: outbox.DestinationType,
+		Topic:           outbox.Topic,
+		OrderingKey:     outbox.OrderingKey,
+		DedupKey:        outbox.DedupKey,
+		ExecuteAt:       strfmt.DateTime(outbox.ExecuteAt),
+		CreatedAt:       strfmt.DateTime(outbox.CreatedAt),
+	}
+
+	if outbox.Payload != nil {
+		resp.Payload = json.RawMessage(outbox.Payload.Bytes)
+	}
+
+	return resp
+}
+
+func ToScheduledEventListResponse(list *models.ScheduledOutboxList) *api_models.ScheduledEventListResponse {
+	data := make([]*api_models.ScheduledEventResponse, len(list.Outboxes))
+	for i := range list.Outboxes {
+		data[i] = ToScheduledEventResponse(&list.Outboxes[i])
+	}
+
+	return &api_models.ScheduledEventListResponse{
+		Data:     data,
+		Total:    list.Total,
+		Page:     int64(list.Page),
+		PageSize: int64(list.PageSize),
+	}
+}
+
+func ToListOutboxAttemptsParam(outboxID string) *models.ListOutboxAttemptsParam {
+	return &models.ListOutboxAttemptsParam{
+		OutboxID: outboxID,
+	}
+}
+
+func ToOutboxAttemptResponse(attempt *models.OutboxAttempt) *api_models.OutboxAttemptResponse {
+	return &api_models.OutboxAttemptResponse{
+		ID:              strfmt.UUID(attempt.ID),
+		OutboxID:        strfmt.UUID(attempt.OutboxID),
+		Attempt:         attempt.Attempt,
+		EventType:       attempt.EventType,
+		DestinationType: attempt.DestinationType,
+		Status:          attempt.Status,
+		WorkerID:        attempt.WorkerID,
+		StartedAt:       strfmt.DateTime(attempt.StartedAt),
+		FinishedAt:      strfmt.DateTime(attempt.FinishedAt),
+		Response:        attempt.Response,
+		ErrorMessage:    attempt.ErrorMessage,
+		BackoffMs:       attempt.BackoffMs,
+	}
+}
+
+func ToOutboxAttemptListResponse(list *models.OutboxAttemptList) *api_models.OutboxAttemptListResponse {
+	data := make([]*api_models.OutboxAttemptResponse, len(list.Attempts))
+	for i := range list.Attempts {
+		data[i] = ToOutboxAttemptResponse(&list.Attempts[i])
+	}
+
+	return &api_models.OutboxAttemptListResponse{
+		Data:     data,
+		Total:    list.Total,
+		Page:     int64(list.Page),
+		PageSize: int64(list.PageSize),
+	}
+}
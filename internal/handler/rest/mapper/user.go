@@ -3,6 +3,8 @@ package mapper
 import (
 	"eventdrivensystem/internal/generated/api_models"
 	models "eventdrivensystem/internal/models/user"
+
+	"github.com/go-openapi/strfmt"
 )
 
 func ToCreateUserParam(request *api_models.CreateUserRequest) *models.CreateUserParam {
@@ -11,3 +13,98 @@ func ToCreateUserParam(request *api_models.CreateUserRequest) *models.CreateUser
 		Password: request.Password,
 	}
 }
+
+func ToVerifyEmailParam(token string) *models.VerifyEmailParam {
+	return &models.VerifyEmailParam{
+		Token: token,
+	}
+}
+
+func ToResendVerificationParam(userID string) *models.ResendVerificationParam {
+	return &models.ResendVerificationParam{
+		UserID: userID,
+	}
+}
+
+func ToRequestPasswordResetParam(request *api_models.PasswordResetRequest) *models.RequestPasswordResetParam {
+	return &models.RequestPasswordResetParam{
+		Email: request.Email,
+	}
+}
+
+func ToConfirmPasswordResetParam(request *api_models.PasswordResetConfirmRequest) *models.ConfirmPasswordResetParam {
+	return &models.ConfirmPasswordResetParam{
+		Token:    request.Token,
+		Password: request.Password,
+	}
+}
+
+func ToGetUserParam(actorID string, actorIsAdmin bool, userID string) *models.GetUserParam {
+	return &models.GetUserParam{
+		ActorID:      actorID,
+		ActorIsAdmin: actorIsAdmin,
+		UserID:       userID,
+	}
+}
+
+func ToListUserParam(email string) *models.ListUserParam {
+	return &models.ListUserParam{
+		Email: email,
+	}
+}
+
+func ToUpdateUserProfileParam(actorID, userID string, request *api_models.UpdateUserProfileRequest) *models.UpdateUserProfileParam {
+	return &models.UpdateUserProfileParam{
+		ActorID:     actorID,
+		UserID:      userID,
+		FullName:    request.FullName,
+		PhoneNumber: request.PhoneNumber,
+	}
+}
+
+func ToChangeUserEmailParam(actorID, userID string, request *api_models.ChangeUserEmailRequest) *models.ChangeUserEmailParam {
+	return &models.ChangeUserEmailParam{
+		ActorID: actorID,
+		UserID:  userID,
+		Email:   request.Email,
+	}
+}
+
+func ToDeleteUserParam(actorID, userID string) *models.DeleteUserParam {
+	return &models.DeleteUserParam{
+		ActorID: actorID,
+		UserID:  userID,
+	}
+}
+
+func ToUserResponse(user *models.User) *api_models.UserResponse {
+	resp := &api_models.UserResponse{
+		ID:          strfmt.UUID(user.ID),
+		Email:       user.Email,
+		FullName:    user.FullName,
+		PhoneNumber: user.PhoneNumber,
+		CreatedAt:   strfmt.DateTime(user.CreatedAt),
+		UpdatedAt:   strfmt.DateTime(user.UpdatedAt),
+	}
+
+	if user.VerifiedAt != nil {
+		verifiedAt := strfmt.DateTime(*user.VerifiedAt)
+		resp.VerifiedAt = &verifiedAt
+	}
+
+	return resp
+}
+
+func ToUserListResponse(list *models.UserList) *api_models.UserListResponse {
+	data := make([]*api_models.UserResponse, len(list.Users))
+	for i := range list.Users {
+		data[i] = ToUserResponse(&list.Users[i])
+	}
+
+	return &api_models.UserListResponse{
+		Data:     data,
+		Total:    list.Total,
+		Page:     int64(list.Page),
+		PageSize: int64(list.PageSize),
+	}
+}
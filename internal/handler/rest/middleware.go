@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"context"
+	authModels "eventdrivensystem/internal/models/auth"
+	"eventdrivensystem/pkg/errors"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Authenticate validates the bearer access token and stores the user id in the
+// request context under the key the logger ContextFields already maps to user_id
+func (r *RouterHandler) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		header := c.Request().Header.Get(echo.HeaderAuthorization)
+		accessToken, found := strings.CutPrefix(header, authModels.TokenTypeBearer+" ")
+		if !found || accessToken == "" {
+			return errors.NewHTTPError(c, errors.ErrUnauthorized)
+		}
+
+		claims, err := r.uc.Auth.VerifyAccessToken(c.Request().Context(), accessToken)
+		if err != nil {
+			return errors.NewHTTPError(c, err)
+		}
+
+		ctx := context.WithValue(c.Request().Context(), authModels.ContextKeyUserID, claims.Subject)
+		c.SetRequest(c.Request().WithContext(ctx))
+		c.Set(authModels.ContextKeyUserID, claims.Subject)
+
+		return next(c)
+	}
+}
+
+// RequireAdmin must run after Authenticate, it rejects users that are not configured as admins
+func (r *RouterHandler) RequireAdmin(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if !r.uc.Auth.IsAdmin(c.Request().Context(), getUserID(c)) {
+			return errors.NewHTTPError(c, errors.ErrForbidden)
+		}
+
+		return next(c)
+	}
+}
+
+func getUserID(c echo.Context) string {
+	userID, _ := c.Get(authModels.ContextKeyUserID).(string)
+	return userID
+}
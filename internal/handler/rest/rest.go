@@ -25,4 +25,6 @@ func (r *RouterHandler) RegisterRoutes() {
 	base := r.echo.Group("/api")
 
 	r.RegisterUserRoutes(base)
+	r.RegisterAuthRoutes(base)
+	r.RegisterAdminRoutes(base)
 }
@@ -5,7 +5,9 @@ import (
 	"eventdrivensystem/internal/handler/rest/mapper"
 	"eventdrivensystem/pkg/errors"
 	"net/http"
+	"strconv"
 
+	"github.com/go-openapi/strfmt"
 	"github.com/labstack/echo/v4"
 )
 
@@ -14,6 +16,14 @@ func (r *RouterHandler) RegisterUserRoutes(base *echo.Group) {
 	{
 
 		v1.POST("", r.CreateUser)
+		v1.GET("/verify", r.VerifyEmail)
+		v1.POST("/verify/resend", r.ResendVerification, r.Authenticate)
+
+		v1.GET("", r.ListUsers, r.Authenticate, r.RequireAdmin)
+		v1.GET("/:id", r.GetUser, r.Authenticate)
+		v1.PATCH("/:id", r.UpdateUserProfile, r.Authenticate)
+		v1.PUT("/:id/email", r.ChangeUserEmail, r.Authenticate)
+		v1.DELETE("/:id", r.DeleteUser, r.Authenticate)
 	}
 }
 
@@ -43,3 +53,157 @@ func (r *RouterHandler) CreateUser(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, resp)
 }
+
+func (r *RouterHandler) VerifyEmail(c echo.Context) error {
+	var (
+		resp api_models.PlainResponse
+		err  error
+	)
+
+	verifyToken := c.QueryParam("token")
+	if verifyToken == "" {
+		return errors.NewHTTPError(c, errors.ErrInvalidVerify)
+	}
+
+	err = r.uc.User.VerifyEmail(c.Request().Context(), mapper.ToVerifyEmailParam(verifyToken))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	resp.Success = true
+	resp.Message = "Email verified successfully"
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *RouterHandler) ResendVerification(c echo.Context) error {
+	var (
+		resp api_models.PlainResponse
+		err  error
+	)
+
+	err = r.uc.User.ResendVerification(c.Request().Context(), mapper.ToResendVerificationParam(getUserID(c)))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	resp.Success = true
+	resp.Message = "Verification email sent"
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *RouterHandler) ListUsers(c echo.Context) error {
+	var (
+		param = mapper.ToListUserParam(c.QueryParam("email"))
+		err   error
+	)
+
+	err = echo.QueryParamsBinder(c).
+		Int("page", &param.Page).
+		Int("page_size", &param.PageSize).
+		BindError()
+	if err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	if verified := c.QueryParam("verified"); verified != "" {
+		v, err := strconv.ParseBool(verified)
+		if err != nil {
+			return errors.NewHTTPError(c, errors.ErrBindRequest)
+		}
+		param.Verified = &v
+	}
+
+	result, err := r.uc.User.ListUsers(c.Request().Context(), param)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, mapper.ToUserListResponse(result))
+}
+
+func (r *RouterHandler) GetUser(c echo.Context) error {
+	var (
+		ctx     = c.Request().Context()
+		actorID = getUserID(c)
+	)
+
+	if !strfmt.IsUUID(c.Param("id")) {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	user, err := r.uc.User.GetUser(ctx, mapper.ToGetUserParam(actorID, r.uc.Auth.IsAdmin(ctx, actorID), c.Param("id")))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, mapper.ToUserResponse(user))
+}
+
+func (r *RouterHandler) UpdateUserProfile(c echo.Context) error {
+	var (
+		req api_models.UpdateUserProfileRequest
+		err error
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = r.validator.Struct(&req)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	user, err := r.uc.User.UpdateUserProfile(c.Request().Context(), mapper.ToUpdateUserProfileParam(getUserID(c), c.Param("id"), &req))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, mapper.ToUserResponse(user))
+}
+
+func (r *RouterHandler) ChangeUserEmail(c echo.Context) error {
+	var (
+		req  api_models.ChangeUserEmailRequest
+		resp api_models.PlainResponse
+		err  error
+	)
+
+	if err := c.Bind(&req); err != nil {
+		return errors.NewHTTPError(c, errors.ErrBindRequest)
+	}
+
+	err = r.validator.Struct(&req)
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	err = r.uc.User.ChangeUserEmail(c.Request().Context(), mapper.ToChangeUserEmailParam(getUserID(c), c.Param("id"), &req))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	resp.Success = true
+	resp.Message = "Email changed, please verify the new address"
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (r *RouterHandler) DeleteUser(c echo.Context) error {
+	var (
+		resp api_models.PlainResponse
+		err  error
+	)
+
+	err = r.uc.User.DeleteUser(c.Request().Context(), mapper.ToDeleteUserParam(getUserID(c), c.Param("id")))
+	if err != nil {
+		return errors.NewHTTPError(c, err)
+	}
+
+	resp.Success = true
+	resp.Message = "User deleted successfully"
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/usecase"
 	"eventdrivensystem/pkg/logger"
 
 	"github.com/hibiken/asynq"
@@ -11,16 +12,22 @@ type WorkerHandler struct {
 	cfg *configs.AppConfig
 	log logger.Logger
 	mux *asynq.ServeMux
+	uc  *usecase.Usecase
 }
 
-func NewWorkerHandler(cfg *configs.AppConfig, log logger.Logger, mux *asynq.ServeMux) *WorkerHandler {
+func NewWorkerHandler(cfg *configs.AppConfig, log logger.Logger, mux *asynq.ServeMux, uc *usecase.Usecase) *WorkerHandler {
 	return &WorkerHandler{
 		cfg: cfg,
 		log: log,
 		mux: mux,
+		uc:  uc,
 	}
 }
 
 func (w *WorkerHandler) RegisterHandlers() {
 	w.RegisterNotificationHandlers()
+	w.RegisterUserHandlers()
+	w.RegisterSchedulerHandlers()
+	w.RegisterSagaHandlers()
+	w.RegisterBillingHandlers()
 }
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	models "eventdrivensystem/internal/models/asynq"
+
+	"github.com/google/uuid"
+)
+
+func (w *WorkerHandler) RegisterBillingHandlers() {
+	w.mux.HandleFunc(models.AsynqTaskBillingProvisionAccount, w.sagaStep(w.handleBillingProvisionAccount))
+	w.mux.HandleFunc(models.AsynqTaskBillingDeprovisionAccount, w.sagaStep(w.handleBillingDeprovisionAccount))
+}
+
+func (w *WorkerHandler) handleBillingProvisionAccount(ctx context.Context, cmd *models.AsynqSagaCommandPayload) (map[string]interface{}, error) {
+	var (
+		param = models.AsynqUserOnboardingSagaData{}
+	)
+
+	if err := json.Unmarshal(cmd.Data, &param); err != nil {
+		return nil, err
+	}
+
+	billingAccountID := uuid.NewString()
+	w.log.InfoWithContext(ctx, "Billing account "+billingAccountID+" provisioned for UserID: "+param.UserID.String())
+
+	return map[string]interface{}{"billing_account_id": billingAccountID}, nil
+}
+
+func (w *WorkerHandler) handleBillingDeprovisionAccount(ctx context.Context, cmd *models.AsynqSagaCommandPayload) (map[string]interface{}, error) {
+	var (
+		param = models.AsynqUserOnboardingSagaData{}
+	)
+
+	if err := json.Unmarshal(cmd.Data, &param); err != nil {
+		return nil, err
+	}
+
+	// a timed out provisioning may not have produced an account id, deprovision by user
+	w.log.InfoWithContext(ctx, "Billing account deprovisioned for UserID: "+param.UserID.String())
+
+	return nil, nil
+}
@@ -3,13 +3,20 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	goErrors "errors"
 	models "eventdrivensystem/internal/models/asynq"
+	userModels "eventdrivensystem/internal/models/user"
+	"eventdrivensystem/pkg/errors"
 
 	"github.com/hibiken/asynq"
 )
 
 func (w *WorkerHandler) RegisterNotificationHandlers() {
 	w.mux.HandleFunc(models.AsynqTaskSendEmailNotification, w.handleSendEmailNotification)
+	w.mux.HandleFunc(models.AsynqTaskSendEmailVerification, w.handleSendEmailVerification)
+	w.mux.HandleFunc(models.AsynqTaskSendPasswordReset, w.handleSendPasswordReset)
+	w.mux.HandleFunc(models.AsynqTaskSendOnboardingTip, w.handleSendOnboardingTip)
+	w.mux.HandleFunc(models.AsynqTaskSendWelcome, w.sagaStep(w.handleSendWelcome))
 }
 
 func (w *WorkerHandler) handleSendEmailNotification(ctx context.Context, task *asynq.Task) error {
@@ -25,3 +32,82 @@ func (w *WorkerHandler) handleSendEmailNotification(ctx context.Context, task *a
 
 	return nil
 }
+
+func (w *WorkerHandler) handleSendEmailVerification(ctx context.Context, task *asynq.Task) error {
+	var (
+		param = models.AsynqSendVerificationPayload{}
+	)
+
+	if err := json.Unmarshal(task.Payload(), &param); err != nil {
+		return err
+	}
+
+	// the token goes into the email only, never into logs
+	_, err := w.uc.User.IssueVerificationLink(ctx, &userModels.IssueVerificationLinkParam{
+		TokenID: param.TokenID.String(),
+	})
+	if err != nil {
+		if goErrors.Is(err, errors.ErrInvalidVerify) {
+			w.log.InfoWithContext(ctx, "Verification email skipped, the token is no longer usable for UserID: "+param.UserID.String())
+			return nil
+		}
+		return err
+	}
+
+	w.log.InfoWithContext(ctx, "Verification email sent for UserID: "+param.UserID.String()+", link expires at "+param.ExpiresAt.String())
+
+	return nil
+}
+
+func (w *WorkerHandler) handleSendPasswordReset(ctx context.Context, task *asynq.Task) error {
+	var (
+		param = models.AsynqSendPasswordResetPayload{}
+	)
+
+	if err := json.Unmarshal(task.Payload(), &param); err != nil {
+		return err
+	}
+
+	_, err := w.uc.User.IssuePasswordResetLink(ctx, &userModels.IssuePasswordResetLinkParam{
+		TokenID: param.TokenID.String(),
+	})
+	if err != nil {
+		if goErrors.Is(err, errors.ErrInvalidReset) {
+			w.log.InfoWithContext(ctx, "Password reset email skipped, the token is no longer usable for UserID: "+param.UserID.String())
+			return nil
+		}
+		return err
+	}
+
+	w.log.InfoWithContext(ctx, "Password reset email sent for UserID: "+param.UserID.String()+", link expires at "+param.ExpiresAt.String())
+
+	return nil
+}
+
+func (w *WorkerHandler) handleSendOnboardingTip(ctx context.Context, task *asynq.Task) error {
+	var (
+		param = models.AsynqSendOnboardingTipPayload{}
+	)
+
+	if err := json.Unmarshal(task.Payload(), &param); err != nil {
+		return err
+	}
+
+	w.log.InfoWithContext(ctx, "Onboarding tip email sent for UserID: "+param.UserID.String())
+
+	return nil
+}
+
+func (w *WorkerHandler) handleSendWelcome(ctx context.Context, cmd *models.AsynqSagaCommandPayload) (map[string]interface{}, error) {
+	var (
+		param = models.AsynqUserOnboardingSagaData{}
+	)
+
+	if err := json.Unmarshal(cmd.Data, &param); err != nil {
+		return nil, err
+	}
+
+	w.log.InfoWithContext(ctx, "Welcome email sent for UserID: "+param.UserID.String()+", billing account: "+param.BillingAccountID)
+
+	return nil, nil
+}
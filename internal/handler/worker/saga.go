@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	goErrors "errors"
+	models "eventdrivensystem/internal/models/asynq"
+	"eventdrivensystem/internal/saga"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+)
+
+// sagaStepFunc runs a saga step or its compensation, the output is merged into the saga data
+type sagaStepFunc func(ctx context.Context, cmd *models.AsynqSagaCommandPayload) (map[string]interface{}, error)
+
+func (w *WorkerHandler) RegisterSagaHandlers() {
+	w.mux.HandleFunc(models.AsynqTaskSagaReply, w.handleSagaReply)
+	w.mux.HandleFunc(models.AsynqTaskSagaTimeout, w.handleSagaTimeout)
+}
+
+// sagaStep adapts fn to an asynq handler whose reply to the orchestrator goes
+// through the outbox, in the transaction of the step. A failing step is retried
+// by asynq first, the failure is only replied on the last attempt or right away
+// when fn wraps asynq.SkipRetry.
+func (w *WorkerHandler) sagaStep(fn sagaStepFunc) asynq.HandlerFunc {
+	return func(ctx context.Context, task *asynq.Task) error {
+		var (
+			cmd = models.AsynqSagaCommandPayload{}
+		)
+
+		if err := json.Unmarshal(task.Payload(), &cmd); err != nil {
+			return err
+		}
+
+		replied, err := w.uc.Saga.RunSagaStep(ctx, &cmd, func(ctx context.Context) (map[string]interface{}, error) {
+			return fn(ctx, &cmd)
+		}, func(err error) bool {
+			return isLastAttempt(ctx, err)
+		})
+		if err == nil {
+			return nil
+		}
+		if !replied {
+			return err
+		}
+
+		// the saga took over the failure, keep the task visible as archived
+		return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+	}
+}
+
+func (w *WorkerHandler) handleSagaReply(ctx context.Context, task *asynq.Task) error {
+	var (
+		param = models.AsynqSagaReplyPayload{}
+	)
+
+	if err := json.Unmarshal(task.Payload(), &param); err != nil {
+		return err
+	}
+
+	return sagaError(w.uc.Saga.HandleSagaReply(ctx, &param))
+}
+
+func (w *WorkerHandler) handleSagaTimeout(ctx context.Context, task *asynq.Task) error {
+	var (
+		param = models.AsynqSagaTimeoutPayload{}
+	)
+
+	if err := json.Unmarshal(task.Payload(), &param); err != nil {
+		return err
+	}
+
+	return sagaError(w.uc.Saga.HandleSagaTimeout(ctx, &param))
+}
+
+func isLastAttempt(ctx context.Context, err error) bool {
+	if goErrors.Is(err, asynq.SkipRetry) {
+		return true
+	}
+
+	retried, ok := asynq.GetRetryCount(ctx)
+	maxRetry, okMax := asynq.GetMaxRetry(ctx)
+
+	return ok && okMax && retried >= maxRetry
+}
+
+// sagaError stops asynq from retrying a message that matches no saga
+func sagaError(err error) error {
+	if goErrors.Is(err, saga.ErrUnknownSaga) {
+		return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+	}
+	return err
+}
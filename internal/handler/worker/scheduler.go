@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	models "eventdrivensystem/internal/models/asynq"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+func (w *WorkerHandler) RegisterSchedulerHandlers() {
+	w.mux.HandleFunc(models.AsynqTaskDailyDigest, w.handleDailyDigest)
+}
+
+func (w *WorkerHandler) handleDailyDigest(ctx context.Context, task *asynq.Task) error {
+	var (
+		param = models.AsynqScheduledJobPayload{}
+	)
+
+	if err := json.Unmarshal(task.Payload(), &param); err != nil {
+		return err
+	}
+
+	w.log.InfoWithContext(ctx, "Daily digest sent for run scheduled at "+param.ScheduledAt.Format(time.RFC3339))
+
+	return nil
+}
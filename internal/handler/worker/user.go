@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	models "eventdrivensystem/internal/models/asynq"
+	userModels "eventdrivensystem/internal/models/user"
+	"strings"
+
+	"github.com/hibiken/asynq"
+)
+
+func (w *WorkerHandler) RegisterUserHandlers() {
+	w.mux.HandleFunc(models.AsynqTaskUserUpdated, w.handleUserUpdated)
+	w.mux.HandleFunc(models.AsynqTaskUserDeleted, w.handleUserDeleted)
+	w.mux.HandleFunc(models.AsynqTaskUserCancelRegistration, w.sagaStep(w.handleUserCancelRegistration))
+}
+
+func (w *WorkerHandler) handleUserUpdated(ctx context.Context, task *asynq.Task) error {
+	var (
+		param = models.AsynqUserEventPayload{}
+	)
+
+	if err := json.Unmarshal(task.Payload(), &param); err != nil {
+		return err
+	}
+
+	w.log.InfoWithContext(ctx, "User updated UserID: "+param.UserID.String()+", fields: "+strings.Join(param.ChangedFields, ","))
+
+	return nil
+}
+
+func (w *WorkerHandler) handleUserDeleted(ctx context.Context, task *asynq.Task) error {
+	var (
+		param = models.AsynqUserEventPayload{}
+	)
+
+	if err := json.Unmarshal(task.Payload(), &param); err != nil {
+		return err
+	}
+
+	w.log.InfoWithContext(ctx, "User deleted UserID: "+param.UserID.String())
+
+	return nil
+}
+
+func (w *WorkerHandler) handleUserCancelRegistration(ctx context.Context, cmd *models.AsynqSagaCommandPayload) (map[string]interface{}, error) {
+	var (
+		param = models.AsynqUserOnboardingSagaData{}
+	)
+
+	if err := json.Unmarshal(cmd.Data, &param); err != nil {
+		return nil, err
+	}
+
+	err := w.uc.User.CancelRegistration(ctx, &userModels.CancelRegistrationParam{
+		UserID: param.UserID.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w.log.InfoWithContext(ctx, "Registration cancelled for UserID: "+param.UserID.String())
+
+	return nil, nil
+}
@@ -0,0 +1,235 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	asynqModels "eventdrivensystem/internal/models/asynq"
+)
+
+const (
+	// Queue is the asynq queue of the generated events, the load generator
+	// consumes it itself so that it sees when each event was handled
+	Queue = "loadgen"
+
+	KindUser          = "user"
+	KindNotification  = "notification"
+	KindUserUpdated   = "user_updated"
+	KindOnboardingTip = "onboarding_tip"
+)
+
+type MixEntry struct {
+	Kind   string
+	Weight int
+}
+
+// Mix is the share of each kind of load, see ParseMix
+type Mix []MixEntry
+
+// ParseMix reads a mix like "notification=70,user=30", every kind must be in allowed
+func ParseMix(s string, allowed ...string) (Mix, error) {
+	var (
+		mix  Mix
+		seen = map[string]bool{}
+	)
+
+	for _, part := range strings.Split(s, ",") {
+		kind, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("mix entry %q is not kind=weight", part)
+		}
+
+		if !contains(allowed, kind) {
+			return nil, fmt.Errorf("unknown kind %q, use one of %s", kind, strings.Join(allowed, ", "))
+		}
+		if seen[kind] {
+			return nil, fmt.Errorf("kind %q is listed twice", kind)
+		}
+		seen[kind] = true
+
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("weight of %q must be a non-negative integer", kind)
+		}
+		mix = append(mix, MixEntry{Kind: kind, Weight: w})
+	}
+
+	if mix.total() == 0 {
+		return nil, fmt.Errorf("mix %q has no weight", s)
+	}
+	return mix, nil
+}
+
+func (m Mix) total() int {
+	total := 0
+	for _, e := range m {
+		total += e.Weight
+	}
+	return total
+}
+
+// Has reports whether kind gets any load
+func (m Mix) Has(kind string) bool {
+	for _, e := range m {
+		if e.Kind == kind && e.Weight > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Pick returns a kind with a probability proportional to its weight
+func (m Mix) Pick(r *rand.Rand) string {
+	n := r.Intn(m.total())
+	for _, e := range m {
+		if n < e.Weight {
+			return e.Kind
+		}
+		n -= e.Weight
+	}
+	return m[len(m)-1].Kind
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary describes a set of latencies
+type Summary struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// Summarize sorts latencies in place and picks the nearest-rank percentiles
+func Summarize(latencies []time.Duration) Summary {
+	if len(latencies) == 0 {
+		return Summary{}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	percentile := func(p float64) time.Duration {
+		rank := int(p*float64(len(latencies))+0.999999) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		return latencies[rank]
+	}
+
+	return Summary{
+		Count: len(latencies),
+		P50:   percentile(0.50),
+		P90:   percentile(0.90),
+		P99:   percentile(0.99),
+		Max:   latencies[len(latencies)-1],
+	}
+}
+
+func (s Summary) String() string {
+	if s.Count == 0 {
+		return "n=0"
+	}
+	return fmt.Sprintf("n=%d p50=%s p90=%s p99=%s max=%s", s.Count,
+		s.P50.Round(time.Millisecond), s.P90.Round(time.Millisecond),
+		s.P99.Round(time.Millisecond), s.Max.Round(time.Millisecond))
+}
+
+// EventKey identifies a generated event from its payload, both when it is
+// created and when the task is handled. Keys of different events can collide,
+// the tracker matches colliding events in creation order.
+func EventKey(eventType string, payload []byte) (string, error) {
+	var fields struct {
+		NotificationID string `json:"notification_id"`
+		UserID         string `json:"user_id"`
+		OccurredAt     string `json:"occurred_at"`
+	}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return "", err
+	}
+
+	switch eventType {
+	case asynqModels.AsynqTaskSendEmailNotification:
+		return eventType + "|" + fields.NotificationID, nil
+	case asynqModels.AsynqTaskUserUpdated:
+		return eventType + "|" + fields.UserID + "|" + fields.OccurredAt, nil
+	default:
+		return eventType + "|" + fields.UserID, nil
+	}
+}
+
+// Tracker matches handled tasks with the events created by the generator
+type Tracker struct {
+	mu      sync.Mutex
+	pending map[string][]time.Time
+	count   int
+	handled map[string][]time.Duration
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		pending: map[string][]time.Time{},
+		handled: map[string][]time.Duration{},
+	}
+}
+
+// Created records that the event with key was committed at createdAt
+func (t *Tracker) Created(key string, createdAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending[key] = append(t.pending[key], createdAt)
+	t.count++
+}
+
+// Handled records the oldest pending event with key as handled at handledAt,
+// it reports false for a task the generator did not create
+func (t *Tracker) Handled(eventType, key string, handledAt time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	created := t.pending[key]
+	if len(created) == 0 {
+		return false
+	}
+
+	t.pending[key] = created[1:]
+	if len(t.pending[key]) == 0 {
+		delete(t.pending, key)
+	}
+	t.count--
+
+	t.handled[eventType] = append(t.handled[eventType], handledAt.Sub(created[0]))
+	return true
+}
+
+// Pending is the number of created events not handled yet
+func (t *Tracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count
+}
+
+// Latencies returns the create to handled latencies by event type
+func (t *Tracker) Latencies() map[string][]time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	latencies := make(map[string][]time.Duration, len(t.handled))
+	for eventType, l := range t.handled {
+		latencies[eventType] = append([]time.Duration(nil), l...)
+	}
+	return latencies
+}
@@ -0,0 +1,75 @@
+package loadgen_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"eventdrivensystem/internal/loadgen"
+	asynqModels "eventdrivensystem/internal/models/asynq"
+
+	"gotest.tools/assert"
+)
+
+func TestParseMix(t *testing.T) {
+	mix, err := loadgen.ParseMix("notification=70, user=30", loadgen.KindNotification, loadgen.KindUser)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, mix, loadgen.Mix{{Kind: "notification", Weight: 70}, {Kind: "user", Weight: 30}})
+
+	_, err = loadgen.ParseMix("notification=70", loadgen.KindUser)
+	assert.ErrorContains(t, err, `unknown kind "notification"`)
+
+	_, err = loadgen.ParseMix("user=0", loadgen.KindUser)
+	assert.ErrorContains(t, err, "has no weight")
+
+	_, err = loadgen.ParseMix("user", loadgen.KindUser)
+	assert.ErrorContains(t, err, "is not kind=weight")
+}
+
+func TestMixPickFollowsWeights(t *testing.T) {
+	mix := loadgen.Mix{{Kind: "a", Weight: 3}, {Kind: "b", Weight: 0}, {Kind: "c", Weight: 1}}
+	r := rand.New(rand.NewSource(1))
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		counts[mix.Pick(r)]++
+	}
+
+	assert.Equal(t, counts["b"], 0)
+	assert.Assert(t, counts["a"] > 2*counts["c"])
+}
+
+func TestSummarize(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	s := loadgen.Summarize(latencies)
+	assert.Equal(t, s.Count, 100)
+	assert.Equal(t, s.P50, 50*time.Millisecond)
+	assert.Equal(t, s.P90, 90*time.Millisecond)
+	assert.Equal(t, s.P99, 99*time.Millisecond)
+	assert.Equal(t, s.Max, 100*time.Millisecond)
+
+	assert.Equal(t, loadgen.Summarize(nil).String(), "n=0")
+}
+
+func TestTrackerMatchesCollidingKeysInOrder(t *testing.T) {
+	tracker := loadgen.NewTracker()
+	start := time.Now()
+
+	key, err := loadgen.EventKey(asynqModels.AsynqTaskSendOnboardingTip, []byte(`{"user_id":"u-1","email":"a@example.com"}`))
+	assert.NilError(t, err)
+
+	tracker.Created(key, start)
+	tracker.Created(key, start.Add(time.Second))
+	assert.Equal(t, tracker.Pending(), 2)
+
+	assert.Assert(t, tracker.Handled(asynqModels.AsynqTaskSendOnboardingTip, key, start.Add(3*time.Second)))
+	assert.Assert(t, tracker.Handled(asynqModels.AsynqTaskSendOnboardingTip, key, start.Add(3*time.Second)))
+	assert.Assert(t, !tracker.Handled(asynqModels.AsynqTaskSendOnboardingTip, key, start.Add(3*time.Second)))
+
+	assert.Equal(t, tracker.Pending(), 0)
+	assert.DeepEqual(t, tracker.Latencies()[asynqModels.AsynqTaskSendOnboardingTip], []time.Duration{3 * time.Second, 2 * time.Second})
+}
@@ -2,4 +2,21 @@ package models
 
 const (
 	AsynqTaskSendEmailNotification string = "email:send_notification"
+	AsynqTaskSendEmailVerification string = "email:send_verification"
+	AsynqTaskSendPasswordReset     string = "email:send_password_reset"
+	AsynqTaskSendOnboardingTip     string = "email:send_onboarding_tip"
+
+	AsynqTaskUserUpdated string = "user.updated"
+	AsynqTaskUserDeleted string = "user.deleted"
+
+	AsynqTaskDailyDigest string = "digest.daily"
+
+	AsynqTaskSagaReply   string = "saga.reply"
+	AsynqTaskSagaTimeout string = "saga.timeout"
+
+	// steps of the user onboarding saga
+	AsynqTaskUserCancelRegistration    string = "user.cancel_registration"
+	AsynqTaskBillingProvisionAccount   string = "billing.provision_account"
+	AsynqTaskBillingDeprovisionAccount string = "billing.deprovision_account"
+	AsynqTaskSendWelcome               string = "email:send_welcome"
 )
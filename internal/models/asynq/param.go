@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/go-openapi/strfmt"
 	"github.com/jackc/pgtype"
 )
@@ -11,6 +14,10 @@ type AsynqSendNotificationPayload struct {
 	NotificationType string       `json:"notification_type"`
 }
 
+func (o *AsynqSendNotificationPayload) EventType() string {
+	return AsynqTaskSendEmailNotification
+}
+
 func (o *AsynqSendNotificationPayload) ToJSON() (*pgtype.JSONB, error) {
 	p := &pgtype.JSONB{}
 	if err := p.Set(o); err != nil {
@@ -18,3 +25,114 @@ func (o *AsynqSendNotificationPayload) ToJSON() (*pgtype.JSONB, error) {
 	}
 	return p, nil
 }
+
+// AsynqSendVerificationPayload refers to the token row, the raw token is only
+// minted by the worker so it is never stored in the outbox
+type AsynqSendVerificationPayload struct {
+	UserID    strfmt.UUID4 `json:"user_id"`
+	Email     string       `json:"email"`
+	TokenID   strfmt.UUID4 `json:"token_id"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+func (o *AsynqSendVerificationPayload) EventType() string {
+	return AsynqTaskSendEmailVerification
+}
+
+// AsynqSendPasswordResetPayload refers to the reset token row, the raw token is
+// only minted by the worker
+type AsynqSendPasswordResetPayload struct {
+	UserID    strfmt.UUID4 `json:"user_id"`
+	Email     string       `json:"email"`
+	TokenID   strfmt.UUID4 `json:"token_id"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+func (o *AsynqSendPasswordResetPayload) EventType() string {
+	return AsynqTaskSendPasswordReset
+}
+
+type AsynqSendOnboardingTipPayload struct {
+	UserID strfmt.UUID4 `json:"user_id"`
+	Email  string       `json:"email"`
+}
+
+func (o *AsynqSendOnboardingTipPayload) EventType() string {
+	return AsynqTaskSendOnboardingTip
+}
+
+// OnboardingTipDedupKey identifies the scheduled onboarding tip of a user
+func OnboardingTipDedupKey(userID strfmt.UUID4) string {
+	return AsynqTaskSendOnboardingTip + ":" + userID.String()
+}
+
+type AsynqUserEventPayload struct {
+	UserID        strfmt.UUID4 `json:"user_id"`
+	Email         string       `json:"email"`
+	ChangedFields []string     `json:"changed_fields,omitempty"`
+	OccurredAt    time.Time    `json:"occurred_at"`
+}
+
+// AsynqUserUpdatedPayload and AsynqUserDeletedPayload share the user event body
+type AsynqUserUpdatedPayload struct {
+	AsynqUserEventPayload
+}
+
+func (o *AsynqUserUpdatedPayload) EventType() string {
+	return AsynqTaskUserUpdated
+}
+
+type AsynqUserDeletedPayload struct {
+	AsynqUserEventPayload
+}
+
+func (o *AsynqUserDeletedPayload) EventType() string {
+	return AsynqTaskUserDeleted
+}
+
+// AsynqScheduledJobPayload is the body of every event fired by the scheduler
+type AsynqScheduledJobPayload struct {
+	Job         string                 `json:"job"`
+	ScheduledAt time.Time              `json:"scheduled_at"`
+	Params      map[string]interface{} `json:"params,omitempty"`
+}
+
+// AsynqSagaCommandPayload is sent to the handler of a saga step, or of its compensation
+type AsynqSagaCommandPayload struct {
+	SagaID       strfmt.UUID4    `json:"saga_id"`
+	Saga         string          `json:"saga"`
+	Step         int             `json:"step"`
+	StepName     string          `json:"step_name"`
+	Compensation bool            `json:"compensation"`
+	Data         json.RawMessage `json:"data"`
+}
+
+// AsynqSagaReplyPayload reports the outcome of a saga command back to the orchestrator
+type AsynqSagaReplyPayload struct {
+	SagaID       strfmt.UUID4           `json:"saga_id"`
+	Step         int                    `json:"step"`
+	Compensation bool                   `json:"compensation"`
+	Success      bool                   `json:"success"`
+	Error        string                 `json:"error,omitempty"`
+	Output       map[string]interface{} `json:"output,omitempty"`
+}
+
+func (o *AsynqSagaReplyPayload) EventType() string {
+	return AsynqTaskSagaReply
+}
+
+type AsynqSagaTimeoutPayload struct {
+	SagaID strfmt.UUID4 `json:"saga_id"`
+	Step   int          `json:"step"`
+}
+
+func (o *AsynqSagaTimeoutPayload) EventType() string {
+	return AsynqTaskSagaTimeout
+}
+
+// AsynqUserOnboardingSagaData is the data of the user onboarding saga
+type AsynqUserOnboardingSagaData struct {
+	UserID           strfmt.UUID4 `json:"user_id"`
+	Email            string       `json:"email"`
+	BillingAccountID string       `json:"billing_account_id,omitempty"`
+}
@@ -0,0 +1,8 @@
+package models
+
+const (
+	TokenTypeBearer string = "Bearer"
+
+	// ContextKeyUserID matches the logger ContextFields mapping for "user_id"
+	ContextKeyUserID string = "x-user-id"
+)
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type LoginParam struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type RefreshTokenParam struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type LogoutParam struct {
+	UserID       string `json:"user_id"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type TokenResult struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string
+	TokenType            string
+}
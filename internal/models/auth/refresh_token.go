@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// RefreshToken model
+type RefreshToken struct {
+	ID        strfmt.UUID4 `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id"`
+	UserID    strfmt.UUID4 `gorm:"type:uuid;not null;column:user_id"`
+	FamilyID  strfmt.UUID4 `gorm:"type:uuid;not null;column:family_id"`
+	TokenHash string       `gorm:"unique;not null;column:token_hash"`
+	ExpiresAt time.Time    `gorm:"not null;column:expires_at"`
+	RevokedAt *time.Time   `gorm:"column:revoked_at"`
+	CreatedAt time.Time    `gorm:"autoCreateTime;column:created_at"`
+}
+
+func (rt *RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+func (rt *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(rt.ExpiresAt)
+}
@@ -6,8 +6,16 @@ const (
 	OutboxStatusSent       string = "SENT"
 	OutboxStatusFailed     string = "FAILED"
 	OutboxStatusRetrying   string = "RETRYING"
+	OutboxStatusCancelled  string = "CANCELLED"
+
+	// OutboxAttemptStatusDeferred is an attempt whose row was held back by a
+	// circuit breaker after it failed, the attempt was given back
+	OutboxAttemptStatusDeferred string = "DEFERRED"
 
 	OutboxDestinationTypeKafka    string = "KAFKA"
 	OutboxDestinationTypeRabbitmq string = "RABBITMQ"
 	OutboxDestinationTypeAsynq    string = "ASYNQ"
+
+	DefaultPageSize int = 20
+	MaxPageSize     int = 100
 )
@@ -15,11 +15,55 @@ type Outbox struct {
 	CreatedAt       time.Time     `json:"created_at" gorm:"column:created_at"`
 	Attempt         int64         `json:"attempt" gorm:"column:attempt"`
 	DestinationType string        `json:"destination_type" gorm:"column:destination_type;not null"`
+	Topic           *string       `json:"topic,omitempty" gorm:"column:topic"`
+	OrderingKey     *string       `json:"ordering_key,omitempty" gorm:"column:ordering_key"`
+	DedupKey        *string       `json:"dedup_key,omitempty" gorm:"column:dedup_key"`
 	SentAt          *time.Time    `json:"sent_at,omitempty" gorm:"column:sent_at"`
 	ErrorMessage    *string       `json:"error_message,omitempty" gorm:"column:error_message"`
 	ExecuteAt       time.Time     `json:"execute_at" gorm:"column:execute_at;not null"`
+	// ProcessingStartedAt is when the relay picked the row, its lease while PROCESSING
+	ProcessingStartedAt *time.Time `json:"processing_started_at,omitempty" gorm:"column:processing_started_at"`
 }
 
 func (Outbox) TableName() string {
 	return "outbox"
 }
+
+// OutboxResult is the outcome of relaying a row. ExecuteAt is the current
+// execute_at of the row, part of its key, NextExecuteAt reschedules a retry.
+// RefundAttempt gives back the attempt taken when the row was fetched.
+// ProcessingStartedAt is the lease the result belongs to, a row requeued by
+// the reaper in the meantime ignores the result.
+type OutboxResult struct {
+	ID                  strfmt.UUID4
+	ExecuteAt           time.Time
+	ProcessingStartedAt time.Time
+	Status              string
+	SentAt              *time.Time
+	NextExecuteAt       *time.Time
+	ErrorMessage        *string
+	RefundAttempt       bool
+	// Attempt is recorded in the attempt history with the result, nil when the row was not published
+	Attempt *OutboxAttempt
+}
+
+// OutboxAttempt is one try to publish an outbox row
+type OutboxAttempt struct {
+	ID              strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	OutboxID        strfmt.UUID4 `json:"outbox_id" gorm:"column:outbox_id;type:uuid;not null"`
+	Attempt         int64        `json:"attempt" gorm:"column:attempt;not null"`
+	EventType       string       `json:"event_type" gorm:"column:event_type;not null"`
+	DestinationType string       `json:"destination_type" gorm:"column:destination_type;not null"`
+	Status          string       `json:"status" gorm:"column:status;not null"`
+	WorkerID        string       `json:"worker_id" gorm:"column:worker_id;not null"`
+	StartedAt       time.Time    `json:"started_at" gorm:"column:started_at;not null"`
+	FinishedAt      time.Time    `json:"finished_at" gorm:"column:finished_at;not null"`
+	Response        *string      `json:"response,omitempty" gorm:"column:response"`
+	ErrorMessage    *string      `json:"error_message,omitempty" gorm:"column:error_message"`
+	BackoffMs       *int64       `json:"backoff_ms,omitempty" gorm:"column:backoff_ms"`
+	CreatedAt       time.Time    `json:"created_at" gorm:"column:created_at;default:CURRENT_TIMESTAMP"`
+}
+
+func (OutboxAttempt) TableName() string {
+	return "outbox_attempts"
+}
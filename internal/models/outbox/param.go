@@ -0,0 +1,73 @@
+package models
+
+type ScheduledOutboxFilter struct {
+	EventType string
+	Limit     int
+	Offset    int
+}
+
+type ListScheduledOutboxParam struct {
+	EventType string `json:"event_type"`
+	Page      int    `json:"page"`
+	PageSize  int    `json:"page_size"`
+}
+
+func (param *ListScheduledOutboxParam) ToFilter() *ScheduledOutboxFilter {
+	if param.Page < 1 {
+		param.Page = 1
+	}
+	if param.PageSize < 1 || param.PageSize > MaxPageSize {
+		param.PageSize = DefaultPageSize
+	}
+
+	return &ScheduledOutboxFilter{
+		EventType: param.EventType,
+		Limit:     param.PageSize,
+		Offset:    (param.Page - 1) * param.PageSize,
+	}
+}
+
+type CancelScheduledOutboxParam struct {
+	DedupKey string `json:"dedup_key"`
+}
+
+type ScheduledOutboxList struct {
+	Outboxes []Outbox
+	Total    int64
+	Page     int
+	PageSize int
+}
+
+type OutboxAttemptFilter struct {
+	OutboxID string
+	Limit    int
+	Offset   int
+}
+
+type ListOutboxAttemptsParam struct {
+	OutboxID string `json:"outbox_id"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+}
+
+func (param *ListOutboxAttemptsParam) ToFilter() *OutboxAttemptFilter {
+	if param.Page < 1 {
+		param.Page = 1
+	}
+	if param.PageSize < 1 || param.PageSize > MaxPageSize {
+		param.PageSize = DefaultPageSize
+	}
+
+	return &OutboxAttemptFilter{
+		OutboxID: param.OutboxID,
+		Limit:    param.PageSize,
+		Offset:   (param.Page - 1) * param.PageSize,
+	}
+}
+
+type OutboxAttemptList struct {
+	Attempts []OutboxAttempt
+	Total    int64
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,21 @@
+package models
+
+const (
+	SagaStatusRunning      string = "RUNNING"
+	SagaStatusCompensating string = "COMPENSATING"
+	SagaStatusCompleted    string = "COMPLETED"
+	SagaStatusCompensated  string = "COMPENSATED"
+	// SagaStatusFailed means a compensation failed and the saga needs manual attention
+	SagaStatusFailed string = "FAILED"
+
+	SagaStepStatusPending            string = "PENDING"
+	SagaStepStatusRunning            string = "RUNNING"
+	SagaStepStatusSucceeded          string = "SUCCEEDED"
+	SagaStepStatusFailed             string = "FAILED"
+	SagaStepStatusTimedOut           string = "TIMED_OUT"
+	SagaStepStatusCompensating       string = "COMPENSATING"
+	SagaStepStatusCompensated        string = "COMPENSATED"
+	SagaStepStatusCompensationFailed string = "COMPENSATION_FAILED"
+
+	SagaUserOnboarding string = "user_onboarding"
+)
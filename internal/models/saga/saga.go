@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgtype"
+)
+
+type Saga struct {
+	ID           strfmt.UUID4  `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	Name         string        `json:"name" gorm:"column:name;not null"`
+	Status       string        `json:"status" gorm:"column:status;not null"`
+	CurrentStep  int           `json:"current_step" gorm:"column:current_step;not null"`
+	Data         *pgtype.JSONB `json:"data" gorm:"type:jsonb;default:'{}';not null"`
+	ErrorMessage *string       `json:"error_message,omitempty" gorm:"column:error_message"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    time.Time     `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (Saga) TableName() string {
+	return "sagas"
+}
+
+type SagaStep struct {
+	ID           strfmt.UUID4 `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	SagaID       strfmt.UUID4 `json:"saga_id" gorm:"column:saga_id;not null"`
+	StepIndex    int          `json:"step_index" gorm:"column:step_index;not null"`
+	Name         string       `json:"name" gorm:"column:name;not null"`
+	Status       string       `json:"status" gorm:"column:status;not null"`
+	ErrorMessage *string      `json:"error_message,omitempty" gorm:"column:error_message"`
+	StartedAt    *time.Time   `json:"started_at,omitempty" gorm:"column:started_at"`
+	DeadlineAt   *time.Time   `json:"deadline_at,omitempty" gorm:"column:deadline_at"`
+	FinishedAt   *time.Time   `json:"finished_at,omitempty" gorm:"column:finished_at"`
+	CreatedAt    time.Time    `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (SagaStep) TableName() string {
+	return "saga_steps"
+}
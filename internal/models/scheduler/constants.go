@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+const (
+	SchedulerRunStatusFired   string = "FIRED"
+	SchedulerRunStatusSkipped string = "SKIPPED"
+
+	// MissedRunPolicyCatchUp fires every run missed while no scheduler was up,
+	// MissedRunPolicySkip only fires the most recent one
+	MissedRunPolicyCatchUp string = "CATCH_UP"
+	MissedRunPolicySkip    string = "SKIP"
+
+	// LockName is hashed into the advisory lock that elects the leader of a tick
+	LockName string = "scheduler"
+
+	DefaultTickInterval   time.Duration = 15 * time.Second
+	DefaultMaxCatchUpRuns int           = 10
+)
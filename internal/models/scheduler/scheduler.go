@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// SchedulerRun is the history of a cron job, one row per scheduled time
+type SchedulerRun struct {
+	ID          strfmt.UUID4  `json:"id" gorm:"type:uuid;default:uuid_generate_v4()"`
+	JobName     string        `json:"job_name" gorm:"column:job_name;not null"`
+	ScheduledAt time.Time     `json:"scheduled_at" gorm:"column:scheduled_at;not null"`
+	Status      string        `json:"status" gorm:"column:status;not null"`
+	OutboxID    *strfmt.UUID4 `json:"outbox_id,omitempty" gorm:"column:outbox_id"`
+	CreatedAt   time.Time     `json:"created_at" gorm:"column:created_at"`
+}
+
+func (SchedulerRun) TableName() string {
+	return "scheduler_runs"
+}
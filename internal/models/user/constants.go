@@ -0,0 +1,10 @@
+package models
+
+const (
+	DefaultPageSize int = 20
+	MaxPageSize     int = 100
+
+	FieldFullName    string = "full_name"
+	FieldPhoneNumber string = "phone_number"
+	FieldEmail       string = "email"
+)
@@ -11,3 +11,93 @@ func (param *CreateUserParam) ToDomain() *User {
 		Password: param.Password,
 	}
 }
+
+type VerifyEmailParam struct {
+	Token string `json:"token"`
+}
+
+type IssueVerificationLinkParam struct {
+	TokenID string `json:"token_id"`
+}
+
+type ResendVerificationParam struct {
+	UserID string `json:"user_id"`
+}
+
+type RequestPasswordResetParam struct {
+	Email string `json:"email"`
+}
+
+type IssuePasswordResetLinkParam struct {
+	TokenID string `json:"token_id"`
+}
+
+type ConfirmPasswordResetParam struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type UserFilter struct {
+	Email    string
+	Verified *bool
+	Limit    int
+	Offset   int
+}
+
+type GetUserParam struct {
+	ActorID      string `json:"actor_id"`
+	ActorIsAdmin bool   `json:"actor_is_admin"`
+	UserID       string `json:"user_id"`
+}
+
+type ListUserParam struct {
+	Email    string `json:"email"`
+	Verified *bool  `json:"verified"`
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+}
+
+func (param *ListUserParam) ToFilter() *UserFilter {
+	if param.Page < 1 {
+		param.Page = 1
+	}
+	if param.PageSize < 1 || param.PageSize > MaxPageSize {
+		param.PageSize = DefaultPageSize
+	}
+
+	return &UserFilter{
+		Email:    param.Email,
+		Verified: param.Verified,
+		Limit:    param.PageSize,
+		Offset:   (param.Page - 1) * param.PageSize,
+	}
+}
+
+type UpdateUserProfileParam struct {
+	ActorID     string  `json:"actor_id"`
+	UserID      string  `json:"user_id"`
+	FullName    *string `json:"full_name"`
+	PhoneNumber *string `json:"phone_number"`
+}
+
+type ChangeUserEmailParam struct {
+	ActorID string `json:"actor_id"`
+	UserID  string `json:"user_id"`
+	Email   string `json:"email"`
+}
+
+type DeleteUserParam struct {
+	ActorID string `json:"actor_id"`
+	UserID  string `json:"user_id"`
+}
+
+type CancelRegistrationParam struct {
+	UserID string `json:"user_id"`
+}
+
+type UserList struct {
+	Users    []User
+	Total    int64
+	Page     int
+	PageSize int
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// PasswordResetToken model
+type PasswordResetToken struct {
+	ID        strfmt.UUID4 `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id"`
+	UserID    strfmt.UUID4 `gorm:"type:uuid;not null;column:user_id"`
+	TokenHash string       `gorm:"unique;not null;column:token_hash"`
+	ExpiresAt time.Time    `gorm:"not null;column:expires_at"`
+	UsedAt    *time.Time   `gorm:"column:used_at"`
+	CreatedAt time.Time    `gorm:"autoCreateTime;column:created_at"`
+}
+
+func (t *PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+)
+
+// EmailVerificationToken model
+type EmailVerificationToken struct {
+	ID        strfmt.UUID4 `gorm:"type:uuid;default:gen_random_uuid();primaryKey;column:id"`
+	UserID    strfmt.UUID4 `gorm:"type:uuid;not null;column:user_id"`
+	TokenHash string       `gorm:"unique;not null;column:token_hash"`
+	ExpiresAt time.Time    `gorm:"not null;column:expires_at"`
+	UsedAt    *time.Time   `gorm:"column:used_at"`
+	CreatedAt time.Time    `gorm:"autoCreateTime;column:created_at"`
+}
+
+func (t *EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
+
+func (t *EmailVerificationToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,373 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	goErrors "errors"
+	"eventdrivensystem/internal/domain/saga"
+	"eventdrivensystem/internal/events"
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	sagaModels "eventdrivensystem/internal/models/saga"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/util"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/jackc/pgtype"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnknownSaga is returned for a reply or timeout that matches no saga step, retrying it cannot help
+var ErrUnknownSaga = goErrors.New("saga: unknown saga or step")
+
+type Orchestrator interface {
+	// Start persists a new saga inside tx and emits the command of its first step.
+	// data must serialize to a JSON object, every step reply is merged into it.
+	Start(ctx context.Context, tx databases.Tx, name string, data interface{}) (strfmt.UUID4, error)
+	// HandleReply moves the saga forward on success and compensates the completed
+	// steps in reverse order on failure. Duplicate and late replies are ignored.
+	HandleReply(ctx context.Context, reply *asynqModels.AsynqSagaReplyPayload) error
+	// HandleTimeout fails the step when it is still waiting for its reply
+	HandleTimeout(ctx context.Context, timeout *asynqModels.AsynqSagaTimeoutPayload) error
+}
+
+type orchestrator struct {
+	registry   *Registry
+	uow        databases.UnitOfWork
+	events     events.Publisher
+	sagaDomain saga.SagaDomainHandler
+}
+
+// NewOrchestrator routes the commands of every registered saga through eventRegistry
+func NewOrchestrator(registry *Registry, eventRegistry *events.Registry, uow databases.UnitOfWork, publisher events.Publisher, sagaDomain saga.SagaDomainHandler) Orchestrator {
+	for _, def := range registry.Definitions() {
+		for _, step := range def.Steps {
+			for _, eventType := range []string{step.Command, step.Compensation} {
+				if eventType == "" {
+					continue
+				}
+				if _, ok := eventRegistry.Route(eventType); ok {
+					continue
+				}
+				eventRegistry.Register(&command{eventType: eventType}, events.Route{
+					Destination: outboxModels.OutboxDestinationTypeAsynq,
+					Topic:       step.Topic,
+				})
+			}
+		}
+	}
+
+	return &orchestrator{
+		registry:   registry,
+		uow:        uow,
+		events:     publisher,
+		sagaDomain: sagaDomain,
+	}
+}
+
+// command is the event of a saga step, its type is only known from the definition
+type command struct {
+	asynqModels.AsynqSagaCommandPayload
+	eventType string
+}
+
+func (c *command) EventType() string {
+	return c.eventType
+}
+
+// stepResult is the outcome of a step, from a reply or from its timeout
+type stepResult struct {
+	compensation bool
+	status       string
+	err          string
+	output       map[string]interface{}
+}
+
+func (o *orchestrator) Start(ctx context.Context, tx databases.Tx, name string, data interface{}) (strfmt.UUID4, error) {
+	def, ok := o.registry.Definition(name)
+	if !ok {
+		return "", fmt.Errorf("saga: %s is not registered", name)
+	}
+
+	payload := &pgtype.JSONB{}
+	if err := payload.Set(data); err != nil {
+		return "", fmt.Errorf("saga: serialize %s data: %w", name, err)
+	}
+
+	s := sagaModels.Saga{
+		Name:   name,
+		Status: sagaModels.SagaStatusRunning,
+		Data:   payload,
+	}
+
+	steps := make([]sagaModels.SagaStep, len(def.Steps))
+	for i, step := range def.Steps {
+		steps[i] = sagaModels.SagaStep{
+			StepIndex: i,
+			Name:      step.Name,
+			Status:    sagaModels.SagaStepStatusPending,
+		}
+	}
+
+	if err := o.sagaDomain.CreateSaga(ctx, &s, steps, tx.DbOptions()); err != nil {
+		return "", err
+	}
+
+	return s.ID, o.advance(ctx, tx, def, &s, steps, 0, time.Now())
+}
+
+func (o *orchestrator) HandleReply(ctx context.Context, reply *asynqModels.AsynqSagaReplyPayload) error {
+	result := stepResult{
+		compensation: reply.Compensation,
+		status:       sagaModels.SagaStepStatusSucceeded,
+		output:       reply.Output,
+	}
+	if !reply.Success {
+		result.status = sagaModels.SagaStepStatusFailed
+		result.err = reply.Error
+	}
+
+	return o.resolve(ctx, reply.SagaID, reply.Step, result)
+}
+
+func (o *orchestrator) HandleTimeout(ctx context.Context, timeout *asynqModels.AsynqSagaTimeoutPayload) error {
+	return o.resolve(ctx, timeout.SagaID, timeout.Step, stepResult{
+		status: sagaModels.SagaStepStatusTimedOut,
+		err:    "step timed out",
+	})
+}
+
+func (o *orchestrator) resolve(ctx context.Context, sagaID strfmt.UUID4, stepIndex int, result stepResult) error {
+	return o.uow.Do(ctx, func(tx databases.Tx) error {
+		s, err := o.sagaDomain.GetSagaByID(ctx, sagaID, util.DbOptions{
+			Transaction: tx.DB(),
+			Clause:      clause.Locking{Strength: "UPDATE"},
+		})
+		if err != nil {
+			if goErrors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUnknownSaga
+			}
+			return err
+		}
+
+		def, ok := o.registry.Definition(s.Name)
+		if !ok {
+			return fmt.Errorf("saga: %s is not registered", s.Name)
+		}
+
+		steps, err := o.sagaDomain.ListSagaSteps(ctx, s.ID, tx.DbOptions())
+		if err != nil {
+			return err
+		}
+
+		if stepIndex < 0 || stepIndex >= len(steps) || len(steps) != len(def.Steps) {
+			return ErrUnknownSaga
+		}
+
+		now := time.Now()
+		step := &steps[stepIndex]
+
+		if result.compensation {
+			if step.Status != sagaModels.SagaStepStatusCompensating {
+				return nil
+			}
+
+			step.FinishedAt = &now
+			if result.status != sagaModels.SagaStepStatusSucceeded {
+				step.Status = sagaModels.SagaStepStatusCompensationFailed
+				step.ErrorMessage = &result.err
+				if err := o.sagaDomain.UpdateSagaStep(ctx, step, tx.DbOptions()); err != nil {
+					return err
+				}
+
+				s.Status = sagaModels.SagaStatusFailed
+				s.ErrorMessage = util.ToPointer("compensation of " + step.Name + " failed: " + result.err)
+				return o.sagaDomain.UpdateSaga(ctx, s, tx.DbOptions())
+			}
+
+			step.Status = sagaModels.SagaStepStatusCompensated
+			if err := o.sagaDomain.UpdateSagaStep(ctx, step, tx.DbOptions()); err != nil {
+				return err
+			}
+
+			return o.compensate(ctx, tx, def, s, steps, stepIndex-1)
+		}
+
+		if step.Status != sagaModels.SagaStepStatusRunning {
+			return nil
+		}
+
+		if result.status != sagaModels.SagaStepStatusTimedOut && step.DeadlineAt != nil {
+			if _, err := o.events.Cancel(ctx, tx, timeoutDedupKey(s.ID, stepIndex)); err != nil {
+				return err
+			}
+		}
+
+		step.Status = result.status
+		step.FinishedAt = &now
+
+		if result.status != sagaModels.SagaStepStatusSucceeded {
+			step.ErrorMessage = &result.err
+			if err := o.sagaDomain.UpdateSagaStep(ctx, step, tx.DbOptions()); err != nil {
+				return err
+			}
+
+			if def.Steps[stepIndex].Optional {
+				return o.advance(ctx, tx, def, s, steps, stepIndex+1, now)
+			}
+
+			s.Status = sagaModels.SagaStatusCompensating
+			s.ErrorMessage = util.ToPointer(step.Name + ": " + result.err)
+
+			// a failed step is assumed to have no effect, a timed out one may still
+			// have run, compensate skips the former and undoes the latter
+			return o.compensate(ctx, tx, def, s, steps, stepIndex)
+		}
+
+		if err := o.sagaDomain.UpdateSagaStep(ctx, step, tx.DbOptions()); err != nil {
+			return err
+		}
+
+		if err := mergeOutput(s, result.output); err != nil {
+			return err
+		}
+
+		return o.advance(ctx, tx, def, s, steps, stepIndex+1, now)
+	})
+}
+
+// advance starts the first step from `from` that has a command, the steps without
+// command on the way succeed immediately. The saga completes after the last step.
+func (o *orchestrator) advance(ctx context.Context, tx databases.Tx, def Definition, s *sagaModels.Saga, steps []sagaModels.SagaStep, from int, now time.Time) error {
+	for i := from; i < len(def.Steps); i++ {
+		step := &steps[i]
+		step.StartedAt = &now
+
+		if def.Steps[i].Command == "" {
+			step.Status = sagaModels.SagaStepStatusSucceeded
+			step.FinishedAt = &now
+			if err := o.sagaDomain.UpdateSagaStep(ctx, step, tx.DbOptions()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		step.Status = sagaModels.SagaStepStatusRunning
+		if def.Steps[i].Timeout > 0 {
+			deadline := now.Add(def.Steps[i].Timeout)
+			step.DeadlineAt = &deadline
+		}
+		if err := o.sagaDomain.UpdateSagaStep(ctx, step, tx.DbOptions()); err != nil {
+			return err
+		}
+
+		if err := o.emit(ctx, tx, def.Steps[i].Command, s, step, false); err != nil {
+			return err
+		}
+
+		if step.DeadlineAt != nil {
+			err := o.events.Publish(ctx, tx, &asynqModels.AsynqSagaTimeoutPayload{
+				SagaID: s.ID,
+				Step:   i,
+			},
+				events.WithExecuteAt(*step.DeadlineAt),
+				events.WithDedupKey(timeoutDedupKey(s.ID, i)),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		s.CurrentStep = i
+		return o.sagaDomain.UpdateSaga(ctx, s, tx.DbOptions())
+	}
+
+	s.Status = sagaModels.SagaStatusCompleted
+	s.CurrentStep = len(def.Steps) - 1
+	return o.sagaDomain.UpdateSaga(ctx, s, tx.DbOptions())
+}
+
+// compensate emits the compensation of the latest step from `from` downwards that
+// took effect, the saga is compensated once no such step is left
+func (o *orchestrator) compensate(ctx context.Context, tx databases.Tx, def Definition, s *sagaModels.Saga, steps []sagaModels.SagaStep, from int) error {
+	now := time.Now()
+
+	for i := from; i >= 0; i-- {
+		step := &steps[i]
+		if step.Status != sagaModels.SagaStepStatusSucceeded && step.Status != sagaModels.SagaStepStatusTimedOut {
+			continue
+		}
+
+		if def.Steps[i].Compensation == "" {
+			step.Status = sagaModels.SagaStepStatusCompensated
+			step.FinishedAt = &now
+			if err := o.sagaDomain.UpdateSagaStep(ctx, step, tx.DbOptions()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		step.Status = sagaModels.SagaStepStatusCompensating
+		if err := o.sagaDomain.UpdateSagaStep(ctx, step, tx.DbOptions()); err != nil {
+			return err
+		}
+
+		if err := o.emit(ctx, tx, def.Steps[i].Compensation, s, step, true); err != nil {
+			return err
+		}
+
+		s.CurrentStep = i
+		return o.sagaDomain.UpdateSaga(ctx, s, tx.DbOptions())
+	}
+
+	s.Status = sagaModels.SagaStatusCompensated
+	s.CurrentStep = 0
+	return o.sagaDomain.UpdateSaga(ctx, s, tx.DbOptions())
+}
+
+func (o *orchestrator) emit(ctx context.Context, tx databases.Tx, eventType string, s *sagaModels.Saga, step *sagaModels.SagaStep, compensation bool) error {
+	return o.events.Publish(ctx, tx, &command{
+		AsynqSagaCommandPayload: asynqModels.AsynqSagaCommandPayload{
+			SagaID:       s.ID,
+			Saga:         s.Name,
+			Step:         step.StepIndex,
+			StepName:     step.Name,
+			Compensation: compensation,
+			Data:         s.Data.Bytes,
+		},
+		eventType: eventType,
+	})
+}
+
+func mergeOutput(s *sagaModels.Saga, output map[string]interface{}) error {
+	if len(output) == 0 {
+		return nil
+	}
+
+	data := map[string]interface{}{}
+	if s.Data != nil && len(s.Data.Bytes) > 0 {
+		if err := json.Unmarshal(s.Data.Bytes, &data); err != nil {
+			return fmt.Errorf("saga: %s data is not an object: %w", s.Name, err)
+		}
+	}
+
+	for k, v := range output {
+		data[k] = v
+	}
+
+	payload := &pgtype.JSONB{}
+	if err := payload.Set(data); err != nil {
+		return err
+	}
+	s.Data = payload
+
+	return nil
+}
+
+func timeoutDedupKey(sagaID strfmt.UUID4, step int) string {
+	return asynqModels.AsynqTaskSagaTimeout + ":" + sagaID.String() + ":" + strconv.Itoa(step)
+}
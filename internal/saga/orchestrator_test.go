@@ -0,0 +1,243 @@
+package saga_test
+
+import (
+	"context"
+	"encoding/json"
+	"eventdrivensystem/internal/events"
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	sagaModels "eventdrivensystem/internal/models/saga"
+	"eventdrivensystem/internal/saga"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/util"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"gorm.io/gorm"
+	"gotest.tools/assert"
+)
+
+type fakeTx struct{}
+
+func (fakeTx) DB() *gorm.DB                             { return nil }
+func (fakeTx) DbOptions() util.DbOptions                { return util.DbOptions{} }
+func (fakeTx) Context() context.Context                 { return context.Background() }
+func (fakeTx) AfterCommit(fn func(ctx context.Context)) {}
+
+type fakeUnitOfWork struct{}
+
+func (fakeUnitOfWork) Do(ctx context.Context, fn func(tx databases.Tx) error, opts ...databases.TxOption) error {
+	return fn(fakeTx{})
+}
+
+type fakePublisher struct {
+	published []string
+	cancelled []string
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, tx databases.Tx, event events.Event, opts ...events.PublishOption) error {
+	p.published = append(p.published, event.EventType())
+	return nil
+}
+
+func (p *fakePublisher) Cancel(ctx context.Context, tx databases.Tx, dedupKey string) (bool, error) {
+	p.cancelled = append(p.cancelled, dedupKey)
+	return true, nil
+}
+
+type fakeSagaDomain struct {
+	sagas map[strfmt.UUID4]sagaModels.Saga
+	steps map[strfmt.UUID4][]sagaModels.SagaStep
+}
+
+func (d *fakeSagaDomain) GetSagaByID(ctx context.Context, id strfmt.UUID4, opts ...util.DbOptions) (*sagaModels.Saga, error) {
+	s, ok := d.sagas[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &s, nil
+}
+
+func (d *fakeSagaDomain) ListSagaSteps(ctx context.Context, sagaID strfmt.UUID4, opts ...util.DbOptions) ([]sagaModels.SagaStep, error) {
+	return append([]sagaModels.SagaStep(nil), d.steps[sagaID]...), nil
+}
+
+func (d *fakeSagaDomain) CreateSaga(ctx context.Context, s *sagaModels.Saga, steps []sagaModels.SagaStep, opts ...util.DbOptions) error {
+	s.ID = strfmt.UUID4(fmt.Sprintf("00000000-0000-4000-8000-%012d", len(d.sagas)+1))
+	for i := range steps {
+		steps[i].SagaID = s.ID
+	}
+	d.sagas[s.ID] = *s
+	d.steps[s.ID] = append([]sagaModels.SagaStep(nil), steps...)
+	return nil
+}
+
+func (d *fakeSagaDomain) UpdateSaga(ctx context.Context, s *sagaModels.Saga, opts ...util.DbOptions) error {
+	d.sagas[s.ID] = *s
+	return nil
+}
+
+func (d *fakeSagaDomain) UpdateSagaStep(ctx context.Context, step *sagaModels.SagaStep, opts ...util.DbOptions) error {
+	d.steps[step.SagaID][step.StepIndex] = *step
+	return nil
+}
+
+func newTestOrchestrator() (saga.Orchestrator, *fakeSagaDomain, *fakePublisher) {
+	registry := saga.NewRegistry()
+	registry.Register(saga.Definition{
+		Name: "order",
+		Steps: []saga.Step{
+			{Name: "create_order", Compensation: "order.cancel"},
+			{Name: "reserve_stock", Command: "stock.reserve", Compensation: "stock.release", Timeout: time.Minute},
+			{Name: "charge", Command: "payment.charge"},
+		},
+	})
+
+	domain := &fakeSagaDomain{
+		sagas: map[strfmt.UUID4]sagaModels.Saga{},
+		steps: map[strfmt.UUID4][]sagaModels.SagaStep{},
+	}
+	publisher := &fakePublisher{}
+
+	return saga.NewOrchestrator(registry, events.NewRegistry(), fakeUnitOfWork{}, publisher, domain), domain, publisher
+}
+
+func stepStatuses(domain *fakeSagaDomain, id strfmt.UUID4) []string {
+	var statuses []string
+	for _, step := range domain.steps[id] {
+		statuses = append(statuses, step.Status)
+	}
+	return statuses
+}
+
+func TestSagaCompletes(t *testing.T) {
+	orchestrator, domain, publisher := newTestOrchestrator()
+	ctx := context.Background()
+
+	id, err := orchestrator.Start(ctx, fakeTx{}, "order", map[string]interface{}{"order_id": "o-1"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, publisher.published, []string{"stock.reserve", asynqModels.AsynqTaskSagaTimeout})
+	assert.DeepEqual(t, stepStatuses(domain, id), []string{
+		sagaModels.SagaStepStatusSucceeded, sagaModels.SagaStepStatusRunning, sagaModels.SagaStepStatusPending,
+	})
+
+	err = orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{
+		SagaID: id, Step: 1, Success: true, Output: map[string]interface{}{"reservation_id": "r-1"},
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(publisher.cancelled), 1)
+	assert.Equal(t, publisher.published[2], "payment.charge")
+
+	err = orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 2, Success: true})
+	assert.NilError(t, err)
+
+	s := domain.sagas[id]
+	assert.Equal(t, s.Status, sagaModels.SagaStatusCompleted)
+
+	var data map[string]interface{}
+	assert.NilError(t, json.Unmarshal(s.Data.Bytes, &data))
+	assert.DeepEqual(t, data, map[string]interface{}{"order_id": "o-1", "reservation_id": "r-1"})
+}
+
+func TestSagaCompensatesInReverseOrder(t *testing.T) {
+	orchestrator, domain, publisher := newTestOrchestrator()
+	ctx := context.Background()
+
+	id, err := orchestrator.Start(ctx, fakeTx{}, "order", map[string]interface{}{})
+	assert.NilError(t, err)
+	assert.NilError(t, orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 1, Success: true}))
+
+	err = orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 2, Success: false, Error: "card declined"})
+	assert.NilError(t, err)
+	assert.Equal(t, domain.sagas[id].Status, sagaModels.SagaStatusCompensating)
+	assert.Equal(t, publisher.published[len(publisher.published)-1], "stock.release")
+
+	err = orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 1, Compensation: true, Success: true})
+	assert.NilError(t, err)
+	assert.Equal(t, publisher.published[len(publisher.published)-1], "order.cancel")
+
+	err = orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 0, Compensation: true, Success: true})
+	assert.NilError(t, err)
+
+	assert.Equal(t, domain.sagas[id].Status, sagaModels.SagaStatusCompensated)
+	assert.DeepEqual(t, stepStatuses(domain, id), []string{
+		sagaModels.SagaStepStatusCompensated, sagaModels.SagaStepStatusCompensated, sagaModels.SagaStepStatusFailed,
+	})
+}
+
+func TestSagaTimeoutCompensatesTheStep(t *testing.T) {
+	orchestrator, domain, publisher := newTestOrchestrator()
+	ctx := context.Background()
+
+	id, err := orchestrator.Start(ctx, fakeTx{}, "order", map[string]interface{}{})
+	assert.NilError(t, err)
+
+	err = orchestrator.HandleTimeout(ctx, &asynqModels.AsynqSagaTimeoutPayload{SagaID: id, Step: 1})
+	assert.NilError(t, err)
+	assert.Equal(t, publisher.published[len(publisher.published)-1], "stock.release")
+
+	// the late reply of the timed out step changes nothing
+	published := len(publisher.published)
+	err = orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 1, Success: true})
+	assert.NilError(t, err)
+	assert.Equal(t, len(publisher.published), published)
+	assert.Equal(t, domain.steps[id][1].Status, sagaModels.SagaStepStatusCompensating)
+}
+
+func TestSagaOptionalStepNeverCompensates(t *testing.T) {
+	registry := saga.NewRegistry()
+	registry.Register(saga.Definition{
+		Name: "signup",
+		Steps: []saga.Step{
+			{Name: "create_user", Compensation: "user.cancel"},
+			{Name: "send_welcome", Command: "email.welcome", Timeout: time.Minute, Optional: true},
+			{Name: "send_tip", Command: "email.tip", Optional: true},
+		},
+	})
+	domain := &fakeSagaDomain{
+		sagas: map[strfmt.UUID4]sagaModels.Saga{},
+		steps: map[strfmt.UUID4][]sagaModels.SagaStep{},
+	}
+	publisher := &fakePublisher{}
+	orchestrator := saga.NewOrchestrator(registry, events.NewRegistry(), fakeUnitOfWork{}, publisher, domain)
+	ctx := context.Background()
+
+	id, err := orchestrator.Start(ctx, fakeTx{}, "signup", map[string]interface{}{})
+	assert.NilError(t, err)
+
+	assert.NilError(t, orchestrator.HandleTimeout(ctx, &asynqModels.AsynqSagaTimeoutPayload{SagaID: id, Step: 1}))
+	assert.Equal(t, publisher.published[len(publisher.published)-1], "email.tip")
+
+	assert.NilError(t, orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 2, Success: false, Error: "smtp down"}))
+
+	assert.Equal(t, domain.sagas[id].Status, sagaModels.SagaStatusCompleted)
+	assert.DeepEqual(t, stepStatuses(domain, id), []string{
+		sagaModels.SagaStepStatusSucceeded, sagaModels.SagaStepStatusTimedOut, sagaModels.SagaStepStatusFailed,
+	})
+	for _, published := range publisher.published {
+		assert.Assert(t, published != "user.cancel")
+	}
+}
+
+func TestSagaCompensationFailure(t *testing.T) {
+	orchestrator, domain, _ := newTestOrchestrator()
+	ctx := context.Background()
+
+	id, err := orchestrator.Start(ctx, fakeTx{}, "order", map[string]interface{}{})
+	assert.NilError(t, err)
+	assert.NilError(t, orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 1, Success: false}))
+	assert.NilError(t, orchestrator.HandleReply(ctx, &asynqModels.AsynqSagaReplyPayload{SagaID: id, Step: 0, Compensation: true, Success: false, Error: "boom"}))
+
+	assert.Equal(t, domain.sagas[id].Status, sagaModels.SagaStatusFailed)
+	assert.Equal(t, domain.steps[id][0].Status, sagaModels.SagaStepStatusCompensationFailed)
+}
+
+func TestSagaUnknownReply(t *testing.T) {
+	orchestrator, _, _ := newTestOrchestrator()
+
+	err := orchestrator.HandleReply(context.Background(), &asynqModels.AsynqSagaReplyPayload{
+		SagaID: "00000000-0000-4000-8000-000000000099", Step: 0, Success: true,
+	})
+	assert.Equal(t, err, saga.ErrUnknownSaga)
+}
@@ -0,0 +1,37 @@
+package saga
+
+import (
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	sagaModels "eventdrivensystem/internal/models/saga"
+	"time"
+)
+
+// DefaultRegistry declares every saga the application runs
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	r.Register(Definition{
+		Name: sagaModels.SagaUserOnboarding,
+		Steps: []Step{
+			{
+				Name:         "create_user",
+				Compensation: asynqModels.AsynqTaskUserCancelRegistration,
+			},
+			{
+				Name:         "provision_billing",
+				Command:      asynqModels.AsynqTaskBillingProvisionAccount,
+				Compensation: asynqModels.AsynqTaskBillingDeprovisionAccount,
+				Timeout:      5 * time.Minute,
+			},
+			{
+				// a missing welcome email must not cancel the registration
+				Name:     "send_welcome",
+				Command:  asynqModels.AsynqTaskSendWelcome,
+				Timeout:  5 * time.Minute,
+				Optional: true,
+			},
+		},
+	})
+
+	return r
+}
@@ -0,0 +1,76 @@
+package saga
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Definition declares a saga as an ordered list of steps
+type Definition struct {
+	Name  string
+	Steps []Step
+}
+
+// Step is one action of a saga. Command and Compensation are task types whose
+// handlers receive an AsynqSagaCommandPayload and reply to the orchestrator.
+type Step struct {
+	Name string
+	// Command is emitted when the step starts, a step without command is done by
+	// the caller of Start and succeeds immediately, it can still be compensated
+	Command string
+	// Compensation undoes the step, empty when there is nothing to undo.
+	// Compensations must be idempotent, a timed out step is compensated too.
+	Compensation string
+	// Topic is the asynq queue of the command and the compensation, empty uses the default queue
+	Topic string
+	// Timeout fails the step when no reply arrived in time, 0 waits forever
+	Timeout time.Duration
+	// Optional steps don't decide the outcome of the saga, a failed or timed out
+	// optional step is recorded and the saga moves on without compensating
+	Optional bool
+}
+
+type Registry struct {
+	mu          sync.RWMutex
+	definitions map[string]Definition
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		definitions: map[string]Definition{},
+	}
+}
+
+// Register adds a saga definition, registering the same name twice panics
+func (r *Registry) Register(def Definition) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.definitions[def.Name]; ok {
+		panic(fmt.Sprintf("saga: %s registered twice", def.Name))
+	}
+	if len(def.Steps) == 0 {
+		panic(fmt.Sprintf("saga: %s has no steps", def.Name))
+	}
+	r.definitions[def.Name] = def
+}
+
+func (r *Registry) Definition(name string) (Definition, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	def, ok := r.definitions[name]
+	return def, ok
+}
+
+func (r *Registry) Definitions() []Definition {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	defs := make([]Definition, 0, len(r.definitions))
+	for _, def := range r.definitions {
+		defs = append(defs, def)
+	}
+	return defs
+}
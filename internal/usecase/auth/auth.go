@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/domain"
+	"eventdrivensystem/internal/domain/auth"
+	"eventdrivensystem/internal/domain/user"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/logger"
+)
+
+type AuthUsecase struct {
+	cfg *configs.AppConfig
+	log logger.Logger
+	uow databases.UnitOfWork
+
+	// domain
+	authDomain auth.AuthDomainHandler
+	userDomain user.UserDomainHandler
+}
+
+type AuthUsecaseHandler interface {
+	AuthUsecaseReader
+	AuthUsecaseWriter
+}
+
+func NewAuthUsecase(
+	cfg *configs.AppConfig,
+	log logger.Logger,
+	dom *domain.Domain,
+) AuthUsecaseHandler {
+	return &AuthUsecase{
+		cfg:        cfg,
+		log:        log,
+		uow:        dom.UnitOfWork,
+		authDomain: dom.Auth,
+		userDomain: dom.User,
+	}
+}
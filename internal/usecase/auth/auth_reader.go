@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+	"eventdrivensystem/pkg/errors"
+	"eventdrivensystem/pkg/token"
+	"slices"
+)
+
+type AuthUsecaseReader interface {
+	VerifyAccessToken(ctx context.Context, accessToken string) (*token.Claims, error)
+	IsAdmin(ctx context.Context, userID string) bool
+}
+
+func (u *AuthUsecase) VerifyAccessToken(ctx context.Context, accessToken string) (*token.Claims, error) {
+	claims, err := token.ParseJWT(u.cfg.Auth.JWTSecret, u.cfg.Auth.Issuer, accessToken)
+	if err != nil {
+		u.log.DebugWithContext(ctx, err)
+		return nil, errors.ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (u *AuthUsecase) IsAdmin(ctx context.Context, userID string) bool {
+	return userID != "" && slices.Contains(u.cfg.Auth.AdminUserIDs, userID)
+}
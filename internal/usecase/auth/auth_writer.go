@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	goErrors "errors"
+	authModels "eventdrivensystem/internal/models/auth"
+	"time"
+
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/errors"
+	"eventdrivensystem/pkg/token"
+	"eventdrivensystem/pkg/util"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+type AuthUsecaseWriter interface {
+	Login(ctx context.Context, param *authModels.LoginParam) (*authModels.TokenResult, error)
+	RefreshToken(ctx context.Context, param *authModels.RefreshTokenParam) (*authModels.TokenResult, error)
+	Logout(ctx context.Context, param *authModels.LogoutParam) error
+}
+
+func (u *AuthUsecase) Login(ctx context.Context, param *authModels.LoginParam) (*authModels.TokenResult, error) {
+	// a replica may not have the user yet right after registration
+	user, err := u.userDomain.GetUserByEmail(ctx, param.Email, util.DbOptions{Clause: dbresolver.Write})
+	if err != nil {
+		if goErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrInvalidLogin
+		}
+		u.log.ErrorWithContext(ctx, err)
+		return nil, errors.ErrSQLGet
+	}
+
+	if !util.ComparePassword(user.Password, param.Password) {
+		return nil, errors.ErrInvalidLogin
+	}
+
+	// every login starts a new refresh token family
+	familyID := strfmt.UUID4(uuid.NewString())
+
+	return u.issueTokens(ctx, user.ID, familyID)
+}
+
+// RefreshToken rotates the refresh token. Presenting a token that was already
+// rotated or revoked is treated as theft and revokes the whole family.
+func (u *AuthUsecase) RefreshToken(ctx context.Context, param *authModels.RefreshTokenParam) (*authModels.TokenResult, error) {
+	var (
+		now    = time.Now()
+		reused bool
+		result *authModels.TokenResult
+	)
+
+	err := u.uow.Do(ctx, func(tx databases.Tx) error {
+		dbOptions := tx.DbOptions()
+		reused = false
+
+		current, err := u.authDomain.GetRefreshTokenByHash(ctx, token.Hash(param.RefreshToken), util.DbOptions{
+			Transaction: tx.DB(),
+			Clause:      clause.Locking{Strength: "UPDATE"},
+		})
+		if err != nil {
+			if goErrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrInvalidToken
+			}
+			return err
+		}
+
+		if current.RevokedAt != nil {
+			// commit the family revocation, the caller still gets an invalid token
+			reused = true
+			u.log.WarnWithContext(ctx, "refresh token reuse detected, revoked family ", current.FamilyID, " of user ", current.UserID)
+			return u.authDomain.RevokeRefreshTokenFamily(ctx, current.FamilyID, now, dbOptions)
+		}
+
+		if current.IsExpired(now) {
+			return errors.ErrInvalidToken
+		}
+
+		err = u.authDomain.RevokeRefreshToken(ctx, current.ID, now, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		result, err = u.issueTokens(ctx, current.UserID, current.FamilyID, dbOptions)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		return nil, errors.ErrInvalidToken
+	}
+
+	return result, nil
+}
+
+// Logout revokes the session family of the given refresh token
+func (u *AuthUsecase) Logout(ctx context.Context, param *authModels.LogoutParam) error {
+	rt, err := u.authDomain.GetRefreshTokenByHash(ctx, token.Hash(param.RefreshToken))
+	if err != nil {
+		if goErrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrInvalidToken
+		}
+		u.log.ErrorWithContext(ctx, err)
+		return errors.ErrSQLGet
+	}
+
+	if rt.UserID.String() != param.UserID {
+		return errors.ErrInvalidToken
+	}
+
+	return u.authDomain.RevokeRefreshTokenFamily(ctx, rt.FamilyID, time.Now())
+}
+
+func (u *AuthUsecase) issueTokens(ctx context.Context, userID, familyID strfmt.UUID4, opts ...util.DbOptions) (*authModels.TokenResult, error) {
+	accessToken, accessExpiresAt, err := token.NewJWT(
+		u.cfg.Auth.JWTSecret,
+		u.cfg.Auth.Issuer,
+		userID.String(),
+		time.Duration(u.cfg.Auth.AccessTokenDurationInMinutes)*time.Minute,
+	)
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return nil, errors.ErrGenerateToken
+	}
+
+	refreshToken, err := token.NewOpaque()
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return nil, errors.ErrGenerateToken
+	}
+
+	_, err = u.authDomain.CreateRefreshToken(ctx, &authModels.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: token.Hash(refreshToken),
+		ExpiresAt: time.Now().Add(time.Duration(u.cfg.Auth.RefreshTokenDurationInHours) * time.Hour),
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authModels.TokenResult{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessExpiresAt,
+		RefreshToken:         refreshToken,
+		TokenType:            authModels.TokenTypeBearer,
+	}, nil
+}
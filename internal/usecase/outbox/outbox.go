@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/domain"
+	"eventdrivensystem/internal/domain/outbox"
+	"eventdrivensystem/internal/events"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/logger"
+)
+
+type OutboxUsecase struct {
+	cfg    *configs.AppConfig
+	log    logger.Logger
+	uow    databases.UnitOfWork
+	events events.Publisher
+
+	// domain
+	outboxDomain outbox.OutboxDomainHandler
+}
+
+type OutboxUsecaseHandler interface {
+	OutboxUsecaseReader
+	OutboxUsecaseWriter
+}
+
+func NewOutboxUsecase(
+	cfg *configs.AppConfig,
+	log logger.Logger,
+	dom *domain.Domain,
+) OutboxUsecaseHandler {
+	return &OutboxUsecase{
+		cfg:          cfg,
+		log:          log,
+		uow:          dom.UnitOfWork,
+		events:       dom.Events,
+		outboxDomain: dom.Outbox,
+	}
+}
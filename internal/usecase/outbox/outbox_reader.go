@@ -0,0 +1,43 @@
+package outbox
+
+import (
+	"context"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/errors"
+	"time"
+)
+
+type OutboxUsecaseReader interface {
+	ListScheduledEvents(ctx context.Context, param *outboxModels.ListScheduledOutboxParam) (*outboxModels.ScheduledOutboxList, error)
+	ListOutboxAttempts(ctx context.Context, param *outboxModels.ListOutboxAttemptsParam) (*outboxModels.OutboxAttemptList, error)
+}
+
+func (u *OutboxUsecase) ListScheduledEvents(ctx context.Context, param *outboxModels.ListScheduledOutboxParam) (*outboxModels.ScheduledOutboxList, error) {
+	outboxes, total, err := u.outboxDomain.ListScheduledOutbox(ctx, param.ToFilter(), time.Now())
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return nil, errors.ErrSQLGet
+	}
+
+	return &outboxModels.ScheduledOutboxList{
+		Outboxes: outboxes,
+		Total:    total,
+		Page:     param.Page,
+		PageSize: param.PageSize,
+	}, nil
+}
+
+func (u *OutboxUsecase) ListOutboxAttempts(ctx context.Context, param *outboxModels.ListOutboxAttemptsParam) (*outboxModels.OutboxAttemptList, error) {
+	attempts, total, err := u.outboxDomain.ListOutboxAttempts(ctx, param.ToFilter())
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return nil, errors.ErrSQLGet
+	}
+
+	return &outboxModels.OutboxAttemptList{
+		Attempts: attempts,
+		Total:    total,
+		Page:     param.Page,
+		PageSize: param.PageSize,
+	}, nil
+}
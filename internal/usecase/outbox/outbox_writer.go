@@ -0,0 +1,31 @@
+package outbox
+
+import (
+	"context"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/errors"
+)
+
+type OutboxUsecaseWriter interface {
+	CancelScheduledEvent(ctx context.Context, param *outboxModels.CancelScheduledOutboxParam) error
+}
+
+// CancelScheduledEvent returns ErrNotFound when no event with the key is pending,
+// including when it already fired
+func (u *OutboxUsecase) CancelScheduledEvent(ctx context.Context, param *outboxModels.CancelScheduledOutboxParam) error {
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		cancelled, err := u.events.Cancel(ctx, tx, param.DedupKey)
+		if err != nil {
+			return err
+		}
+
+		if !cancelled {
+			return errors.ErrNotFound
+		}
+
+		u.log.InfoWithContext(ctx, "Cancelled scheduled event with dedup key: "+param.DedupKey)
+
+		return nil
+	})
+}
@@ -0,0 +1,36 @@
+package saga
+
+import (
+	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/domain"
+	"eventdrivensystem/internal/events"
+	"eventdrivensystem/internal/saga"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/logger"
+)
+
+type SagaUsecase struct {
+	cfg    *configs.AppConfig
+	log    logger.Logger
+	uow    databases.UnitOfWork
+	events events.Publisher
+	sagas  saga.Orchestrator
+}
+
+type SagaUsecaseHandler interface {
+	SagaUsecaseWriter
+}
+
+func NewSagaUsecase(
+	cfg *configs.AppConfig,
+	log logger.Logger,
+	dom *domain.Domain,
+) SagaUsecaseHandler {
+	return &SagaUsecase{
+		cfg:    cfg,
+		log:    log,
+		uow:    dom.UnitOfWork,
+		events: dom.Events,
+		sagas:  dom.Sagas,
+	}
+}
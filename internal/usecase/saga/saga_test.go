@@ -0,0 +1,110 @@
+package saga
+
+import (
+	"context"
+	goErrors "errors"
+	"eventdrivensystem/internal/events"
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/util"
+	"testing"
+
+	"gorm.io/gorm"
+	"gotest.tools/assert"
+)
+
+type fakeTxKey struct{}
+
+// fakeTx collects the events published in it, they count once the outermost unit of work commits
+type fakeTx struct {
+	ctx     context.Context
+	pending *[]events.Event
+}
+
+func (t *fakeTx) DB() *gorm.DB                             { return nil }
+func (t *fakeTx) DbOptions() util.DbOptions                { return util.DbOptions{} }
+func (t *fakeTx) Context() context.Context                 { return t.ctx }
+func (t *fakeTx) AfterCommit(fn func(ctx context.Context)) {}
+
+type fakeUnitOfWork struct {
+	committed []events.Event
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(tx databases.Tx) error, opts ...databases.TxOption) error {
+	if parent, ok := ctx.Value(fakeTxKey{}).(*fakeTx); ok {
+		return fn(parent)
+	}
+
+	var pending []events.Event
+	t := &fakeTx{pending: &pending}
+	t.ctx = context.WithValue(ctx, fakeTxKey{}, t)
+
+	if err := fn(t); err != nil {
+		return err
+	}
+	u.committed = append(u.committed, pending...)
+	return nil
+}
+
+type fakePublisher struct{}
+
+func (fakePublisher) Publish(ctx context.Context, tx databases.Tx, event events.Event, opts ...events.PublishOption) error {
+	t := tx.(*fakeTx)
+	*t.pending = append(*t.pending, event)
+	return nil
+}
+
+func (fakePublisher) Cancel(ctx context.Context, tx databases.Tx, dedupKey string) (bool, error) {
+	return false, nil
+}
+
+func newTestUsecase() (*SagaUsecase, *fakeUnitOfWork) {
+	uow := &fakeUnitOfWork{}
+	return &SagaUsecase{
+		uow:    uow,
+		events: fakePublisher{},
+	}, uow
+}
+
+func TestRunSagaStepRepliesInItsTransaction(t *testing.T) {
+	u, uow := newTestUsecase()
+	cmd := &asynqModels.AsynqSagaCommandPayload{SagaID: "00000000-0000-4000-8000-000000000001", Step: 1}
+
+	replied, err := u.RunSagaStep(context.Background(), cmd, func(ctx context.Context) (map[string]interface{}, error) {
+		_, inTx := ctx.Value(fakeTxKey{}).(*fakeTx)
+		assert.Assert(t, inTx)
+		return map[string]interface{}{"billing_account_id": "b1"}, nil
+	}, func(err error) bool { return false })
+
+	assert.NilError(t, err)
+	assert.Assert(t, replied)
+	assert.Equal(t, len(uow.committed), 1)
+
+	reply := uow.committed[0].(*asynqModels.AsynqSagaReplyPayload)
+	assert.Equal(t, reply.Step, 1)
+	assert.Assert(t, reply.Success)
+	assert.DeepEqual(t, reply.Output, map[string]interface{}{"billing_account_id": "b1"})
+}
+
+func TestRunSagaStepRepliesFailureOnlyWhenFinal(t *testing.T) {
+	u, uow := newTestUsecase()
+	cmd := &asynqModels.AsynqSagaCommandPayload{SagaID: "00000000-0000-4000-8000-000000000001", Step: 2, Compensation: true}
+	failed := goErrors.New("billing down")
+	step := func(ctx context.Context) (map[string]interface{}, error) {
+		return nil, failed
+	}
+
+	replied, err := u.RunSagaStep(context.Background(), cmd, step, func(err error) bool { return false })
+	assert.Equal(t, err, failed)
+	assert.Assert(t, !replied)
+	assert.Equal(t, len(uow.committed), 0)
+
+	replied, err = u.RunSagaStep(context.Background(), cmd, step, func(err error) bool { return true })
+	assert.Equal(t, err, failed)
+	assert.Assert(t, replied)
+	assert.Equal(t, len(uow.committed), 1)
+
+	reply := uow.committed[0].(*asynqModels.AsynqSagaReplyPayload)
+	assert.Assert(t, !reply.Success && reply.Compensation)
+	assert.Equal(t, reply.Error, "billing down")
+}
@@ -0,0 +1,69 @@
+package saga
+
+import (
+	"context"
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	"eventdrivensystem/pkg/databases"
+	"strconv"
+)
+
+// SagaStepFunc runs a saga step or its compensation, the output is merged into
+// the saga data. Writes through a unit of work on ctx join the transaction of the reply.
+type SagaStepFunc func(ctx context.Context) (map[string]interface{}, error)
+
+type SagaUsecaseWriter interface {
+	RunSagaStep(ctx context.Context, cmd *asynqModels.AsynqSagaCommandPayload, step SagaStepFunc, final func(err error) bool) (replied bool, err error)
+	HandleSagaReply(ctx context.Context, reply *asynqModels.AsynqSagaReplyPayload) error
+	HandleSagaTimeout(ctx context.Context, timeout *asynqModels.AsynqSagaTimeoutPayload) error
+}
+
+// RunSagaStep runs step and publishes its reply through the outbox in the same
+// transaction, the reply handler then advances the saga. A failed step has its
+// writes rolled back to a savepoint. Its failure is only replied when final
+// returns true for it, otherwise nothing is committed and the step can be retried.
+// replied reports whether the reply was committed, err is the step error then.
+func (u *SagaUsecase) RunSagaStep(ctx context.Context, cmd *asynqModels.AsynqSagaCommandPayload, step SagaStepFunc, final func(err error) bool) (replied bool, err error) {
+	var stepErr error
+
+	err = u.uow.Do(ctx, func(tx databases.Tx) error {
+		var output map[string]interface{}
+		stepErr = u.uow.Do(tx.Context(), func(inner databases.Tx) error {
+			var err error
+			output, err = step(inner.Context())
+			return err
+		})
+		if stepErr != nil && !final(stepErr) {
+			return stepErr
+		}
+
+		reply := &asynqModels.AsynqSagaReplyPayload{
+			SagaID:       cmd.SagaID,
+			Step:         cmd.Step,
+			Compensation: cmd.Compensation,
+			Success:      stepErr == nil,
+			Output:       output,
+		}
+		if stepErr != nil {
+			reply.Error = stepErr.Error()
+		}
+
+		return u.events.Publish(ctx, tx, reply)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, stepErr
+}
+
+func (u *SagaUsecase) HandleSagaReply(ctx context.Context, reply *asynqModels.AsynqSagaReplyPayload) error {
+	if !reply.Success {
+		u.log.WarnWithContext(ctx, "Saga "+reply.SagaID.String()+" step "+strconv.Itoa(reply.Step)+" failed: "+reply.Error)
+	}
+
+	return u.sagas.HandleReply(ctx, reply)
+}
+
+func (u *SagaUsecase) HandleSagaTimeout(ctx context.Context, timeout *asynqModels.AsynqSagaTimeoutPayload) error {
+	return u.sagas.HandleTimeout(ctx, timeout)
+}
@@ -0,0 +1,39 @@
+package scheduler
+
+import (
+	"eventdrivensystem/configs"
+	"eventdrivensystem/internal/domain"
+	"eventdrivensystem/internal/domain/outbox"
+	"eventdrivensystem/internal/domain/scheduler"
+	"eventdrivensystem/pkg/databases"
+	"eventdrivensystem/pkg/logger"
+)
+
+type SchedulerUsecase struct {
+	cfg *configs.AppConfig
+	log logger.Logger
+	uow databases.UnitOfWork
+
+	// domain
+	schedulerDomain scheduler.SchedulerDomainHandler
+	outboxDomain    outbox.OutboxDomainHandler
+}
+
+type SchedulerUsecaseHandler interface {
+	SchedulerUsecaseReader
+	SchedulerUsecaseWriter
+}
+
+func NewSchedulerUsecase(
+	cfg *configs.AppConfig,
+	log logger.Logger,
+	dom *domain.Domain,
+) SchedulerUsecaseHandler {
+	return &SchedulerUsecase{
+		cfg:             cfg,
+		log:             log,
+		uow:             dom.UnitOfWork,
+		schedulerDomain: dom.Scheduler,
+		outboxDomain:    dom.Outbox,
+	}
+}
@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"eventdrivensystem/configs"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	schedulerModels "eventdrivensystem/internal/models/scheduler"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+type SchedulerUsecaseReader interface {
+	// ValidateJobs parses every configured job, the scheduler command refuses to start on error
+	ValidateJobs() error
+}
+
+type cronJob struct {
+	configs.SchedulerJob
+	schedule cron.Schedule
+}
+
+func (u *SchedulerUsecase) ValidateJobs() error {
+	_, err := u.parseJobs()
+	return err
+}
+
+// parseJobs reads the jobs from the config on every call so a reloaded config applies on the next tick
+func (u *SchedulerUsecase) parseJobs() ([]cronJob, error) {
+	jobs := make([]cronJob, 0, len(u.cfg.Scheduler.Jobs))
+	seen := map[string]bool{}
+
+	for _, job := range u.cfg.Scheduler.Jobs {
+		if seen[job.Name] {
+			return nil, fmt.Errorf("scheduler job %s: defined twice", job.Name)
+		}
+		seen[job.Name] = true
+
+		schedule, err := cron.ParseStandard(job.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler job %s: %w", job.Name, err)
+		}
+
+		if job.Destination == "" {
+			job.Destination = outboxModels.OutboxDestinationTypeAsynq
+		}
+		if job.MissedRunPolicy == "" {
+			job.MissedRunPolicy = schedulerModels.MissedRunPolicySkip
+		}
+
+		jobs = append(jobs, cronJob{SchedulerJob: job, schedule: schedule})
+	}
+
+	return jobs, nil
+}
+
+// dueRuns returns the scheduled times in (after, now], keeping only the latest
+// max of them, and how many older ones were dropped
+func (j *cronJob) dueRuns(after, now time.Time, max int) ([]time.Time, int) {
+	var (
+		due     []time.Time
+		dropped int
+	)
+
+	// a zero time means the spec never fires again
+	for t := j.schedule.Next(after); !t.IsZero() && !t.After(now); t = j.schedule.Next(t) {
+		due = append(due, t)
+		if len(due) > max {
+			due = due[1:]
+			dropped++
+		}
+	}
+
+	return due, dropped
+}
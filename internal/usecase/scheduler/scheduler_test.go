@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"eventdrivensystem/configs"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	schedulerModels "eventdrivensystem/internal/models/scheduler"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func newTestUsecase(jobs ...configs.SchedulerJob) *SchedulerUsecase {
+	return &SchedulerUsecase{
+		cfg: &configs.AppConfig{
+			Scheduler: configs.Scheduler{Jobs: jobs},
+		},
+	}
+}
+
+func TestParseJobs(t *testing.T) {
+	u := newTestUsecase(configs.SchedulerJob{Name: "digest", Spec: "0 8 * * *", EventType: "digest.daily"})
+
+	jobs, err := u.parseJobs()
+	assert.NilError(t, err)
+	assert.Equal(t, len(jobs), 1)
+	assert.Equal(t, jobs[0].Destination, outboxModels.OutboxDestinationTypeAsynq)
+	assert.Equal(t, jobs[0].MissedRunPolicy, schedulerModels.MissedRunPolicySkip)
+}
+
+func TestParseJobsInvalid(t *testing.T) {
+	u := newTestUsecase(configs.SchedulerJob{Name: "digest", Spec: "not a spec", EventType: "digest.daily"})
+	assert.ErrorContains(t, u.ValidateJobs(), "scheduler job digest")
+
+	u = newTestUsecase(
+		configs.SchedulerJob{Name: "digest", Spec: "0 8 * * *", EventType: "digest.daily"},
+		configs.SchedulerJob{Name: "digest", Spec: "0 9 * * *", EventType: "digest.daily"},
+	)
+	assert.ErrorContains(t, u.ValidateJobs(), "defined twice")
+}
+
+func TestDueRuns(t *testing.T) {
+	jobs, err := newTestUsecase(configs.SchedulerJob{Name: "hourly", Spec: "0 * * * *", EventType: "x"}).parseJobs()
+	assert.NilError(t, err)
+	job := jobs[0]
+
+	after := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// nothing due before the next hour
+	due, dropped := job.dueRuns(after, after.Add(59*time.Minute), 10)
+	assert.Equal(t, len(due), 0)
+	assert.Equal(t, dropped, 0)
+
+	// the boundary itself is due
+	due, _ = job.dueRuns(after, after.Add(time.Hour), 10)
+	assert.DeepEqual(t, due, []time.Time{after.Add(time.Hour)})
+
+	// five hours down with a limit of three keeps the latest three
+	due, dropped = job.dueRuns(after, after.Add(5*time.Hour+time.Minute), 3)
+	assert.Equal(t, dropped, 2)
+	assert.DeepEqual(t, due, []time.Time{after.Add(3 * time.Hour), after.Add(4 * time.Hour), after.Add(5 * time.Hour)})
+}
@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"context"
+	goErrors "errors"
+	asynqModels "eventdrivensystem/internal/models/asynq"
+	outboxModels "eventdrivensystem/internal/models/outbox"
+	schedulerModels "eventdrivensystem/internal/models/scheduler"
+	"eventdrivensystem/pkg/databases"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgtype"
+	"gorm.io/gorm"
+)
+
+type SchedulerUsecaseWriter interface {
+	// RunDueJobs fires every job run due at now. Only the instance holding the
+	// scheduler advisory lock does the work, the others return nil right away.
+	RunDueJobs(ctx context.Context, now time.Time) error
+}
+
+func (u *SchedulerUsecase) RunDueJobs(ctx context.Context, now time.Time) error {
+	jobs, err := u.parseJobs()
+	if err != nil {
+		return err
+	}
+
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		leader, err := u.schedulerDomain.TryLock(ctx, schedulerModels.LockName, tx.DbOptions())
+		if err != nil {
+			return err
+		}
+
+		if !leader {
+			u.log.DebugWithContext(ctx, "Another scheduler instance holds the lock, skipping tick")
+			return nil
+		}
+
+		for _, job := range jobs {
+			// each job runs in a savepoint, a failing job is retried on the next tick
+			// without holding back the others
+			err := u.uow.Do(tx.Context(), func(jobTx databases.Tx) error {
+				return u.runJob(ctx, jobTx, job, now)
+			})
+			if err != nil {
+				u.log.ErrorWithContext(ctx, fmt.Sprintf("Error running scheduler job %s: %v", job.Name, err))
+			}
+		}
+
+		return nil
+	})
+}
+
+func (u *SchedulerUsecase) runJob(ctx context.Context, tx databases.Tx, job cronJob, now time.Time) error {
+	dbOptions := tx.DbOptions()
+
+	// a new job starts with the run due in the current tick instead of its whole past
+	after := now.Add(-u.tickInterval())
+
+	lastRun, err := u.schedulerDomain.GetLatestRun(ctx, job.Name, dbOptions)
+	if err != nil && !goErrors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if lastRun != nil {
+		after = lastRun.ScheduledAt
+	}
+
+	due, dropped := job.dueRuns(after, now, u.maxCatchUpRuns())
+	if dropped > 0 {
+		u.log.WarnWithContext(ctx, fmt.Sprintf("Scheduler job %s missed %d runs beyond the catch up limit", job.Name, dropped))
+	}
+
+	for i, scheduledAt := range due {
+		run := schedulerModels.SchedulerRun{
+			JobName:     job.Name,
+			ScheduledAt: scheduledAt,
+			Status:      schedulerModels.SchedulerRunStatusSkipped,
+		}
+
+		if job.MissedRunPolicy == schedulerModels.MissedRunPolicyCatchUp || i == len(due)-1 {
+			outbox, err := u.createJobOutbox(ctx, tx, job, scheduledAt, now)
+			if err != nil {
+				return err
+			}
+
+			run.Status = schedulerModels.SchedulerRunStatusFired
+			run.OutboxID = &outbox.ID
+		}
+
+		if err := u.schedulerDomain.CreateRun(ctx, &run, dbOptions); err != nil {
+			return err
+		}
+
+		u.log.InfoWithContext(ctx, fmt.Sprintf("Scheduler job %s %s run scheduled at %s", job.Name, run.Status, scheduledAt.Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+func (u *SchedulerUsecase) createJobOutbox(ctx context.Context, tx databases.Tx, job cronJob, scheduledAt, now time.Time) (*outboxModels.Outbox, error) {
+	payload := &pgtype.JSONB{}
+	err := payload.Set(&asynqModels.AsynqScheduledJobPayload{
+		Job:         job.Name,
+		ScheduledAt: scheduledAt,
+		Params:      job.Params,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("serialize %s payload: %w", job.Name, err)
+	}
+
+	outbox := outboxModels.Outbox{
+		Payload:         payload,
+		EventType:       job.EventType,
+		DestinationType: job.Destination,
+		ExecuteAt:       now,
+	}
+	if job.Topic != "" {
+		outbox.Topic = &job.Topic
+	}
+
+	if err := u.outboxDomain.CreateOutbox(ctx, &outbox, tx.DbOptions()); err != nil {
+		return nil, err
+	}
+
+	return &outbox, nil
+}
+
+func (u *SchedulerUsecase) tickInterval() time.Duration {
+	if u.cfg.Scheduler.TickIntervalInSeconds > 0 {
+		return time.Duration(u.cfg.Scheduler.TickIntervalInSeconds) * time.Second
+	}
+	return schedulerModels.DefaultTickInterval
+}
+
+func (u *SchedulerUsecase) maxCatchUpRuns() int {
+	if u.cfg.Scheduler.MaxCatchUpRuns > 0 {
+		return u.cfg.Scheduler.MaxCatchUpRuns
+	}
+	return schedulerModels.DefaultMaxCatchUpRuns
+}
@@ -3,12 +3,20 @@ package usecase
 import (
 	"eventdrivensystem/configs"
 	"eventdrivensystem/internal/domain"
+	"eventdrivensystem/internal/usecase/auth"
+	"eventdrivensystem/internal/usecase/outbox"
+	"eventdrivensystem/internal/usecase/saga"
+	"eventdrivensystem/internal/usecase/scheduler"
 	"eventdrivensystem/internal/usecase/user"
 	"eventdrivensystem/pkg/logger"
 )
 
 type Usecase struct {
-	User user.UserUsecaseHandler
+	User      user.UserUsecaseHandler
+	Auth      auth.AuthUsecaseHandler
+	Outbox    outbox.OutboxUsecaseHandler
+	Scheduler scheduler.SchedulerUsecaseHandler
+	Saga      saga.SagaUsecaseHandler
 }
 
 func NewUsecase(
@@ -17,6 +25,10 @@ func NewUsecase(
 	dom *domain.Domain,
 ) *Usecase {
 	return &Usecase{
-		User: user.NewUserUsecase(cfg, log, dom),
+		User:      user.NewUserUsecase(cfg, log, dom),
+		Auth:      auth.NewAuthUsecase(cfg, log, dom),
+		Outbox:    outbox.NewOutboxUsecase(cfg, log, dom),
+		Scheduler: scheduler.NewSchedulerUsecase(cfg, log, dom),
+		Saga:      saga.NewSagaUsecase(cfg, log, dom),
 	}
 }
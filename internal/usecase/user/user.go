@@ -3,23 +3,30 @@ package user
 import (
 	"eventdrivensystem/configs"
 	"eventdrivensystem/internal/domain"
+	"eventdrivensystem/internal/domain/auth"
 	"eventdrivensystem/internal/domain/notification"
-	"eventdrivensystem/internal/domain/outbox"
 	"eventdrivensystem/internal/domain/user"
+	"eventdrivensystem/internal/events"
+	"eventdrivensystem/internal/saga"
+	"eventdrivensystem/pkg/databases"
 	"eventdrivensystem/pkg/logger"
 )
 
 type UserUsecase struct {
-	cfg *configs.AppConfig
-	log logger.Logger
+	cfg    *configs.AppConfig
+	log    logger.Logger
+	uow    databases.UnitOfWork
+	events events.Publisher
+	sagas  saga.Orchestrator
 
 	// domain
 	userDomain         user.UserDomainHandler
-	outboxDomain       outbox.OutboxDomainHandler
+	authDomain         auth.AuthDomainHandler
 	notificationDomain notification.NotificationDomainHandler
 }
 
 type UserUsecaseHandler interface {
+	UserUsecaseReader
 	UserUsecaseWriter
 }
 
@@ -31,8 +38,11 @@ func NewUserUsecase(
 	return &UserUsecase{
 		cfg:                cfg,
 		log:                log,
+		uow:                dom.UnitOfWork,
+		events:             dom.Events,
+		sagas:              dom.Sagas,
 		userDomain:         dom.User,
-		outboxDomain:       dom.Outbox,
+		authDomain:         dom.Auth,
 		notificationDomain: dom.Notification,
 	}
 }
@@ -0,0 +1,49 @@
+package user
+
+import (
+	"context"
+	goErrors "errors"
+	userModels "eventdrivensystem/internal/models/user"
+	"eventdrivensystem/pkg/errors"
+
+	"github.com/go-openapi/strfmt"
+	"gorm.io/gorm"
+)
+
+type UserUsecaseReader interface {
+	GetUser(ctx context.Context, param *userModels.GetUserParam) (*userModels.User, error)
+	ListUsers(ctx context.Context, param *userModels.ListUserParam) (*userModels.UserList, error)
+}
+
+// GetUser returns the user to the user itself or to an admin
+func (u *UserUsecase) GetUser(ctx context.Context, param *userModels.GetUserParam) (*userModels.User, error) {
+	if param.ActorID != param.UserID && !param.ActorIsAdmin {
+		return nil, errors.ErrForbidden
+	}
+
+	user, err := u.userDomain.GetUserByID(ctx, strfmt.UUID4(param.UserID))
+	if err != nil {
+		if goErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrNotFound
+		}
+		u.log.ErrorWithContext(ctx, err)
+		return nil, errors.ErrSQLGet
+	}
+
+	return user, nil
+}
+
+func (u *UserUsecase) ListUsers(ctx context.Context, param *userModels.ListUserParam) (*userModels.UserList, error) {
+	users, total, err := u.userDomain.ListUsers(ctx, param.ToFilter())
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return nil, errors.ErrSQLGet
+	}
+
+	return &userModels.UserList{
+		Users:    users,
+		Total:    total,
+		Page:     param.Page,
+		PageSize: param.PageSize,
+	}, nil
+}
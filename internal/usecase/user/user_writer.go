@@ -2,92 +2,528 @@ package user
 
 import (
 	"context"
+	goErrors "errors"
+	"eventdrivensystem/internal/events"
 	asynqModels "eventdrivensystem/internal/models/asynq"
 	notificationModels "eventdrivensystem/internal/models/notification"
-	outboxModels "eventdrivensystem/internal/models/outbox"
+	sagaModels "eventdrivensystem/internal/models/saga"
 	userModels "eventdrivensystem/internal/models/user"
 
 	"time"
 
+	"eventdrivensystem/pkg/databases"
 	"eventdrivensystem/pkg/errors"
+	"eventdrivensystem/pkg/token"
 	"eventdrivensystem/pkg/util"
+
+	"github.com/go-openapi/strfmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserUsecaseWriter interface {
 	CreateUser(ctx context.Context, param *userModels.CreateUserParam) error
+	VerifyEmail(ctx context.Context, param *userModels.VerifyEmailParam) error
+	ResendVerification(ctx context.Context, param *userModels.ResendVerificationParam) error
+	IssueVerificationLink(ctx context.Context, param *userModels.IssueVerificationLinkParam) (string, error)
+	RequestPasswordReset(ctx context.Context, param *userModels.RequestPasswordResetParam) error
+	IssuePasswordResetLink(ctx context.Context, param *userModels.IssuePasswordResetLinkParam) (string, error)
+	ConfirmPasswordReset(ctx context.Context, param *userModels.ConfirmPasswordResetParam) error
+	UpdateUserProfile(ctx context.Context, param *userModels.UpdateUserProfileParam) (*userModels.User, error)
+	ChangeUserEmail(ctx context.Context, param *userModels.ChangeUserEmailParam) error
+	DeleteUser(ctx context.Context, param *userModels.DeleteUserParam) error
+	CancelRegistration(ctx context.Context, param *userModels.CancelRegistrationParam) error
 }
 
 func (u *UserUsecase) CreateUser(ctx context.Context, param *userModels.CreateUserParam) error {
-	dbTx := u.userDomain.BeginTx(ctx)
-	var (
-		err error
-		now = time.Now()
-	)
+	now := time.Now()
+
+	hashedPassword, err := util.HashPassword(param.Password)
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return errors.ErrInternal
+	}
+
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		dbOptions := tx.DbOptions()
+
+		newUser := param.ToDomain()
+		newUser.Password = hashedPassword
 
-	defer func() {
-		if tmpErr := util.FirstNotNil(recover(), err); tmpErr != nil {
-			if tmpErr != err {
-				u.log.ErrorWithContext(ctx, tmpErr)
-				return
+		user, err := u.userDomain.CreateUser(ctx, newUser, dbOptions)
+		if err != nil {
+			if goErrors.Is(err, errors.ErrConflict) {
+				return errors.ErrEmailExists
 			}
+			return err
+		}
+
+		pNotif := notificationModels.Notification{
+			Status:  notificationModels.NotificationStatusPending,
+			UserID:  user.ID,
+			Type:    notificationModels.NotificationTypeUserRegistration,
+			Message: notificationModels.NotificationMessageUserRegistration,
+		}
+
+		notif, err := u.notificationDomain.CreateNotification(ctx, &pNotif, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		err = u.events.Publish(ctx, tx, &asynqModels.AsynqSendNotificationPayload{
+			UserID:           user.ID,
+			NotificationID:   notif.ID,
+			NotificationType: notif.Type,
+		})
+		if err != nil {
+			return err
+		}
 
-			if errRollback := dbTx.Rollback().Error; errRollback != nil {
-				u.log.ErrorWithContext(ctx, errRollback)
-				return
+		if u.cfg.Onboarding.TipDelayInHours > 0 {
+			tip := &asynqModels.AsynqSendOnboardingTipPayload{
+				UserID: user.ID,
+				Email:  user.Email,
 			}
-		} else {
-			if errCommit := dbTx.Commit().Error; errCommit != nil {
-				u.log.ErrorWithContext(ctx, errCommit)
-				err = errors.ErrSQLTx
-				return
+			err = u.events.Publish(ctx, tx, tip,
+				events.WithExecuteAt(now.Add(time.Duration(u.cfg.Onboarding.TipDelayInHours)*time.Hour)),
+				events.WithDedupKey(asynqModels.OnboardingTipDedupKey(user.ID)),
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = u.createVerification(ctx, tx, user, now)
+		if err != nil {
+			return err
+		}
+
+		if !u.cfg.Onboarding.SagaEnabled {
+			return nil
+		}
+
+		_, err = u.sagas.Start(ctx, tx, sagaModels.SagaUserOnboarding, &asynqModels.AsynqUserOnboardingSagaData{
+			UserID: user.ID,
+			Email:  user.Email,
+		})
+		return err
+	})
+}
+
+func (u *UserUsecase) VerifyEmail(ctx context.Context, param *userModels.VerifyEmailParam) error {
+	now := time.Now()
+
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		dbOptions := tx.DbOptions()
+
+		vt, err := u.userDomain.GetVerificationTokenByHash(ctx, token.Hash(param.Token), util.DbOptions{
+			Transaction: tx.DB(),
+			Clause:      clause.Locking{Strength: "UPDATE"},
+		})
+		if err != nil {
+			if goErrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrInvalidVerify
 			}
+			return err
+		}
+
+		if !vt.IsUsable(now) {
+			return errors.ErrInvalidVerify
+		}
+
+		// consuming every outstanding token keeps older links from being replayed
+		err = u.userDomain.ConsumeVerificationTokens(ctx, vt.UserID, now, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		return u.userDomain.MarkUserVerified(ctx, vt.UserID, now, dbOptions)
+	})
+}
+
+// ResendVerification checks the resend limits and issues the new token under
+// the lock of the user row, so concurrent requests can't all pass the checks
+func (u *UserUsecase) ResendVerification(ctx context.Context, param *userModels.ResendVerificationParam) error {
+	now := time.Now()
+
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		dbOptions := tx.DbOptions()
+
+		user, err := u.lockUser(ctx, tx, param.UserID)
+		if err != nil {
+			return err
 		}
-	}()
 
-	dbOptions := util.DbOptions{
-		Transaction: dbTx,
+		if user.VerifiedAt != nil {
+			return errors.ErrAlreadyVerified
+		}
+
+		total, latest, err := u.userDomain.CountVerificationTokensSince(ctx, user.ID, now.Add(-time.Hour), dbOptions)
+		if err != nil {
+			return err
+		}
+
+		cooldown := time.Duration(u.cfg.Verification.ResendCooldownInSeconds) * time.Second
+		if latest != nil && now.Sub(*latest) < cooldown {
+			return errors.ErrTooManyRequests
+		}
+		if u.cfg.Verification.MaxResendPerHour > 0 && total >= int64(u.cfg.Verification.MaxResendPerHour) {
+			return errors.ErrTooManyRequests
+		}
+
+		// a resent link supersedes the previous ones
+		err = u.userDomain.ConsumeVerificationTokens(ctx, user.ID, now, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		return u.createVerification(ctx, tx, user, now)
+	})
+}
+
+// IssueVerificationLink mints the raw token of a verification token right before
+// its email is sent and stores only its hash. It returns errors.ErrInvalidVerify
+// when the token was used, superseded by a resend or has expired.
+func (u *UserUsecase) IssueVerificationLink(ctx context.Context, param *userModels.IssueVerificationLinkParam) (string, error) {
+	rawToken, err := token.NewOpaque()
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return "", errors.ErrGenerateToken
 	}
-	user, err := u.userDomain.CreateUser(ctx, param.ToDomain(), dbOptions)
 
+	err = u.userDomain.RotateVerificationToken(ctx, strfmt.UUID4(param.TokenID), token.Hash(rawToken), time.Now())
 	if err != nil {
-		return err
+		if goErrors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.ErrInvalidVerify
+		}
+		u.log.ErrorWithContext(ctx, err)
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// RequestPasswordReset emails a reset link. It returns nil for unknown emails
+// and throttled requests so the response never reveals which accounts exist.
+func (u *UserUsecase) RequestPasswordReset(ctx context.Context, param *userModels.RequestPasswordResetParam) error {
+	user, err := u.userDomain.GetUserByEmail(ctx, param.Email)
+	if err != nil {
+		if goErrors.Is(err, gorm.ErrRecordNotFound) {
+			u.log.DebugWithContext(ctx, "password reset requested for unknown email")
+			return nil
+		}
+		u.log.ErrorWithContext(ctx, err)
+		return errors.ErrSQLGet
 	}
 
-	pNotif := notificationModels.Notification{
-		Status:  notificationModels.NotificationStatusPending,
-		UserID:  user.ID,
-		Type:    notificationModels.NotificationTypeUserRegistration,
-		Message: notificationModels.NotificationMessageUserRegistration,
+	now := time.Now()
+	latest, err := u.userDomain.GetLatestPasswordResetToken(ctx, user.ID)
+	if err != nil && !goErrors.Is(err, gorm.ErrRecordNotFound) {
+		u.log.ErrorWithContext(ctx, err)
+		return errors.ErrSQLGet
+	}
+	cooldown := time.Duration(u.cfg.Auth.PasswordResetCooldownInSeconds) * time.Second
+	if err == nil && now.Sub(latest.CreatedAt) < cooldown {
+		u.log.InfoWithContext(ctx, "password reset throttled for user ", user.ID)
+		return nil
 	}
 
-	notif, err := u.notificationDomain.CreateNotification(ctx, &pNotif, dbOptions)
+	// the worker replaces this hash with the one of the emailed token
+	placeholder, err := token.NewOpaque()
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return errors.ErrGenerateToken
+	}
+
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		dbOptions := tx.DbOptions()
+
+		// only the most recent link can be used
+		err := u.userDomain.ConsumePasswordResetTokens(ctx, user.ID, now, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		rt, err := u.userDomain.CreatePasswordResetToken(ctx, &userModels.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: token.Hash(placeholder),
+			ExpiresAt: now.Add(time.Duration(u.cfg.Auth.PasswordResetTokenDurationInMinutes) * time.Minute),
+		}, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		return u.events.Publish(ctx, tx, &asynqModels.AsynqSendPasswordResetPayload{
+			UserID:    user.ID,
+			Email:     user.Email,
+			TokenID:   rt.ID,
+			ExpiresAt: rt.ExpiresAt,
+		})
+	})
+}
+
+// IssuePasswordResetLink mints the raw token of a reset token right before its
+// email is sent and stores only its hash. It returns errors.ErrInvalidReset when
+// the token was used, superseded by a newer request or has expired.
+func (u *UserUsecase) IssuePasswordResetLink(ctx context.Context, param *userModels.IssuePasswordResetLinkParam) (string, error) {
+	rawToken, err := token.NewOpaque()
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return "", errors.ErrGenerateToken
+	}
+
+	err = u.userDomain.RotatePasswordResetToken(ctx, strfmt.UUID4(param.TokenID), token.Hash(rawToken), time.Now())
+	if err != nil {
+		if goErrors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.ErrInvalidReset
+		}
+		u.log.ErrorWithContext(ctx, err)
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// ConfirmPasswordReset sets the new password, consumes the token and signs the
+// user out everywhere by revoking all refresh tokens
+func (u *UserUsecase) ConfirmPasswordReset(ctx context.Context, param *userModels.ConfirmPasswordResetParam) error {
+	now := time.Now()
+
+	hashedPassword, err := util.HashPassword(param.Password)
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return errors.ErrInternal
+	}
+
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		dbOptions := tx.DbOptions()
+
+		rt, err := u.userDomain.GetPasswordResetTokenByHash(ctx, token.Hash(param.Token), util.DbOptions{
+			Transaction: tx.DB(),
+			Clause:      clause.Locking{Strength: "UPDATE"},
+		})
+		if err != nil {
+			if goErrors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.ErrInvalidReset
+			}
+			return err
+		}
+
+		if !rt.IsUsable(now) {
+			return errors.ErrInvalidReset
+		}
+
+		err = u.userDomain.ConsumePasswordResetTokens(ctx, rt.UserID, now, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		err = u.userDomain.UpdateUserPassword(ctx, rt.UserID, hashedPassword, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		return u.authDomain.RevokeUserRefreshTokens(ctx, rt.UserID, now, dbOptions)
+	})
+}
+
+func (u *UserUsecase) UpdateUserProfile(ctx context.Context, param *userModels.UpdateUserProfileParam) (*userModels.User, error) {
+	if param.ActorID != param.UserID {
+		return nil, errors.ErrForbidden
+	}
+
+	var (
+		now     = time.Now()
+		updated *userModels.User
+	)
+
+	err := u.uow.Do(ctx, func(tx databases.Tx) error {
+		dbOptions := tx.DbOptions()
+
+		user, err := u.lockUser(ctx, tx, param.UserID)
+		if err != nil {
+			return err
+		}
+
+		var changedFields []string
+		if param.FullName != nil {
+			user.FullName = param.FullName
+			changedFields = append(changedFields, userModels.FieldFullName)
+		}
+		if param.PhoneNumber != nil {
+			user.PhoneNumber = param.PhoneNumber
+			changedFields = append(changedFields, userModels.FieldPhoneNumber)
+		}
+
+		updated = user
+		if len(changedFields) == 0 {
+			return nil
+		}
+
+		user.UpdatedAt = now
+		err = u.userDomain.UpdateUserProfile(ctx, user, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		return u.events.Publish(ctx, tx, &asynqModels.AsynqUserUpdatedPayload{
+			AsynqUserEventPayload: newUserEventPayload(user, changedFields, now),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// ChangeUserEmail switches the email, the new address must be verified again
+func (u *UserUsecase) ChangeUserEmail(ctx context.Context, param *userModels.ChangeUserEmailParam) error {
+	if param.ActorID != param.UserID {
+		return errors.ErrForbidden
+	}
+
+	now := time.Now()
+
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		dbOptions := tx.DbOptions()
+
+		user, err := u.lockUser(ctx, tx, param.UserID)
+		if err != nil {
+			return err
+		}
+
+		if user.Email == param.Email {
+			return nil
+		}
+
+		err = u.userDomain.UpdateUserEmail(ctx, user.ID, param.Email, dbOptions)
+		if err != nil {
+			if goErrors.Is(err, errors.ErrConflict) {
+				return errors.ErrEmailExists
+			}
+			return err
+		}
+		user.Email = param.Email
+		user.VerifiedAt = nil
+
+		err = u.userDomain.ConsumeVerificationTokens(ctx, user.ID, now, dbOptions)
+		if err != nil {
+			return err
+		}
+
+		err = u.createVerification(ctx, tx, user, now)
+		if err != nil {
+			return err
+		}
+
+		return u.events.Publish(ctx, tx, &asynqModels.AsynqUserUpdatedPayload{
+			AsynqUserEventPayload: newUserEventPayload(user, []string{userModels.FieldEmail}, now),
+		})
+	})
+}
+
+// DeleteUser soft deletes the user and revokes every session
+func (u *UserUsecase) DeleteUser(ctx context.Context, param *userModels.DeleteUserParam) error {
+	if param.ActorID != param.UserID {
+		return errors.ErrForbidden
+	}
+
+	return u.uow.Do(ctx, func(tx databases.Tx) error {
+		return u.deleteUser(ctx, tx, param.UserID, time.Now())
+	})
+}
+
+// CancelRegistration compensates the creation of a user in the onboarding saga,
+// a user that is already gone counts as cancelled
+func (u *UserUsecase) CancelRegistration(ctx context.Context, param *userModels.CancelRegistrationParam) error {
+	err := u.uow.Do(ctx, func(tx databases.Tx) error {
+		return u.deleteUser(ctx, tx, param.UserID, time.Now())
+	})
+	if goErrors.Is(err, errors.ErrNotFound) {
+		return nil
+	}
+
+	return err
+}
+
+func (u *UserUsecase) deleteUser(ctx context.Context, tx databases.Tx, userID string, now time.Time) error {
+	dbOptions := tx.DbOptions()
+
+	user, err := u.lockUser(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = u.userDomain.SoftDeleteUser(ctx, user.ID, now, dbOptions)
 	if err != nil {
 		return err
 	}
 
-	asynqPayload := asynqModels.AsynqSendNotificationPayload{
-		UserID:           user.ID,
-		NotificationID:   notif.ID,
-		NotificationType: notif.Type,
+	err = u.authDomain.RevokeUserRefreshTokens(ctx, user.ID, now, dbOptions)
+	if err != nil {
+		return err
 	}
 
-	asynqJson, err := asynqPayload.ToJSON()
+	_, err = u.events.Cancel(ctx, tx, asynqModels.OnboardingTipDedupKey(user.ID))
 	if err != nil {
-		err = errors.ErrParseJsonOutbox
 		return err
 	}
 
-	outbox := outboxModels.Outbox{
-		Payload:         asynqJson,
-		DestinationType: outboxModels.OutboxDestinationTypeAsynq,
-		EventType:       asynqModels.AsynqTaskSendEmailNotification,
-		ExecuteAt:       now,
+	return u.events.Publish(ctx, tx, &asynqModels.AsynqUserDeletedPayload{
+		AsynqUserEventPayload: newUserEventPayload(user, nil, now),
+	})
+}
+
+// lockUser loads an active user with FOR UPDATE inside the unit of work
+func (u *UserUsecase) lockUser(ctx context.Context, tx databases.Tx, userID string) (*userModels.User, error) {
+	user, err := u.userDomain.GetUserByID(ctx, strfmt.UUID4(userID), util.DbOptions{
+		Transaction: tx.DB(),
+		Clause:      clause.Locking{Strength: "UPDATE"},
+	})
+	if err != nil {
+		if goErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func newUserEventPayload(user *userModels.User, changedFields []string, now time.Time) asynqModels.AsynqUserEventPayload {
+	return asynqModels.AsynqUserEventPayload{
+		UserID:        user.ID,
+		Email:         user.Email,
+		ChangedFields: changedFields,
+		OccurredAt:    now,
+	}
+}
+
+// createVerification stores a verification token and publishes its email event
+// in the same transaction, so a token is never created without its outbox entry.
+// The stored hash belongs to a discarded token, the worker mints the emailed
+// one with IssueVerificationLink.
+func (u *UserUsecase) createVerification(ctx context.Context, tx databases.Tx, user *userModels.User, now time.Time) error {
+	placeholder, err := token.NewOpaque()
+	if err != nil {
+		u.log.ErrorWithContext(ctx, err)
+		return errors.ErrGenerateToken
 	}
 
-	err = u.outboxDomain.CreateOutbox(ctx, &outbox, dbOptions)
+	vt, err := u.userDomain.CreateVerificationToken(ctx, &userModels.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: token.Hash(placeholder),
+		ExpiresAt: now.Add(time.Duration(u.cfg.Verification.TokenDurationInHours) * time.Hour),
+	}, tx.DbOptions())
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return u.events.Publish(ctx, tx, &asynqModels.AsynqSendVerificationPayload{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenID:   vt.ID,
+		ExpiresAt: vt.ExpiresAt,
+	})
 }
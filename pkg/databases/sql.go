@@ -2,22 +2,33 @@ package databases
 
 import (
 	"eventdrivensystem/configs"
+	"eventdrivensystem/docs/db/migrations"
+	"eventdrivensystem/pkg/errors"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/labstack/gommon/log"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 	"gorm.io/plugin/opentelemetry/tracing"
 
 	migrate "github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres" // Required for postgres driver
-	_ "github.com/golang-migrate/migrate/v4/source/file"       // Required for file source
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file" // Required for file source
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
+// NewSqlDb connects to the primary and, when SQL.ReplicaDSNs is set, registers the
+// replicas with dbresolver. Queries outside a transaction go to a replica, writes,
+// transactions and queries with the dbresolver.Write clause go to the primary.
 func NewSqlDb(cfg *configs.AppConfig) (db *gorm.DB, err error) {
 	db, err = gorm.Open(postgres.New(postgres.Config{
-		DSN: cfg.SQL.DSN,
+		DSN: withStatementTimeout(cfg.SQL.DSN, cfg.SQL.StatementTimeoutInMs),
 	}), &gorm.Config{
 		SkipDefaultTransaction: true,
 	})
@@ -27,32 +38,136 @@ func NewSqlDb(cfg *configs.AppConfig) (db *gorm.DB, err error) {
 
 	db.Use(tracing.NewPlugin())
 
+	if err = registerErrorTranslator(db); err != nil {
+		return
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return
 	}
 
 	// Create the connection pool
-	sqlDB.SetConnMaxIdleTime(time.Minute * 5)
+	sqlDB.SetConnMaxIdleTime(time.Duration(cfg.SQL.ConnMaxIdleTimeInSeconds) * time.Second)
 
 	// SetMaxIdleConns sets the maximum number of connections in the idle connection pool.
-	sqlDB.SetMaxIdleConns(5)
+	sqlDB.SetMaxIdleConns(cfg.SQL.MaxIdleConns)
 
 	// SetMaxOpenConns sets the maximum number of open connections to the database.
-	sqlDB.SetMaxOpenConns(30)
+	sqlDB.SetMaxOpenConns(cfg.SQL.MaxOpenConns)
 
 	// SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
-	sqlDB.SetConnMaxLifetime(620 * time.Second)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.SQL.ConnMaxLifetimeInSeconds) * time.Second)
+
+	if len(cfg.SQL.ReplicaDSNs) > 0 {
+		if err = registerReplicas(db, cfg); err != nil {
+			return
+		}
+	}
 
-	log.Info("Database connected successfully", cfg.SQL.DSN)
+	log.Info("Database connected successfully ", RedactDSN(cfg.SQL.DSN))
 	return db, nil
 }
 
+// registerReplicas routes reads to the replicas, each replica gets the pool settings of the primary
+func registerReplicas(db *gorm.DB, cfg *configs.AppConfig) error {
+	replicas := make([]gorm.Dialector, len(cfg.SQL.ReplicaDSNs))
+	for i, dsn := range cfg.SQL.ReplicaDSNs {
+		replicas[i] = postgres.Open(withStatementTimeout(dsn, cfg.SQL.StatementTimeoutInMs))
+		log.Info("Database replica registered ", RedactDSN(dsn))
+	}
+
+	return db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetConnMaxIdleTime(time.Duration(cfg.SQL.ConnMaxIdleTimeInSeconds) * time.Second).
+		SetMaxIdleConns(cfg.SQL.MaxIdleConns).
+		SetMaxOpenConns(cfg.SQL.MaxOpenConns).
+		SetConnMaxLifetime(time.Duration(cfg.SQL.ConnMaxLifetimeInSeconds) * time.Second))
+}
+
+// withStatementTimeout sets the statement_timeout run-time parameter of every
+// connection opened with dsn, both the URL and the key=value form are supported
+func withStatementTimeout(dsn string, timeoutInMs int) string {
+	if timeoutInMs <= 0 {
+		return dsn
+	}
+
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("statement_timeout", strconv.Itoa(timeoutInMs))
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+
+	return dsn + " statement_timeout=" + strconv.Itoa(timeoutInMs)
+}
+
+var dsnPassword = regexp.MustCompile(`password=('(?:[^'\\]|\\.)*'|\S+)`)
+
+// RedactDSN hides the password of dsn so that it can be logged
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "xxxxx")
+		}
+		q := u.Query()
+		if q.Has("password") {
+			q.Set("password", "xxxxx")
+			u.RawQuery = q.Encode()
+		}
+		return u.String()
+	}
+
+	return dsnPassword.ReplaceAllString(dsn, "password=xxxxx")
+}
+
+// registerErrorTranslator converts driver errors into pkg/errors sentinels after
+// every statement, so domain code can use errors.Is without importing pgx or pq
+func registerErrorTranslator(db *gorm.DB) error {
+	translate := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			tx.Error = errors.TranslateSQL(tx.Error)
+		}
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("errors:translate_create", translate); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("errors:translate_query", translate); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("errors:translate_update", translate); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("errors:translate_delete", translate); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("errors:translate_row", translate); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("errors:translate_raw", translate)
+}
+
+// NewMigrationSource opens the migrations at path, a golang-migrate source URL
+// like file://./docs/db/migrations, or the ones embedded in the binary when path is empty
+func NewMigrationSource(path string) (source.Driver, error) {
+	if path == "" {
+		return iofs.New(migrations.FS, ".")
+	}
+	return source.Open(path)
+}
+
 func NewMigrate(cfg *configs.AppConfig) (*migrate.Migrate, error) {
-	migrationsPath := "file://./docs/db/migrations"
+	src, err := NewMigrationSource(cfg.SQL.MigrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations: %w", err)
+	}
 
 	// Create the migrator instance
-	migrator, err := migrate.New(migrationsPath, cfg.SQL.DSN)
+	migrator, err := migrate.NewWithSourceInstance("migrations", src, cfg.SQL.DSN)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create migrator instance: %w", err)
 	}
@@ -0,0 +1,43 @@
+package databases
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestRedactDSN(t *testing.T) {
+	cases := map[string]string{
+		"postgres://app:s3cret@db:5432/events?sslmode=disable": "postgres://app:xxxxx@db:5432/events?sslmode=disable",
+		"postgres://db:5432/events?password=s3cret":            "postgres://db:5432/events?password=xxxxx",
+		"postgres://db:5432/events":                            "postgres://db:5432/events",
+		"host=db user=app password=s3cret dbname=events":       "host=db user=app password=xxxxx dbname=events",
+		"host=db user=app password='s3 cret' dbname=events":    "host=db user=app password=xxxxx dbname=events",
+	}
+
+	for dsn, want := range cases {
+		assert.Equal(t, RedactDSN(dsn), want)
+	}
+}
+
+func TestWithStatementTimeout(t *testing.T) {
+	assert.Equal(t, withStatementTimeout("postgres://db/events?sslmode=disable", 0), "postgres://db/events?sslmode=disable")
+	assert.Equal(t, withStatementTimeout("postgres://db/events?sslmode=disable", 5000), "postgres://db/events?sslmode=disable&statement_timeout=5000")
+	assert.Equal(t, withStatementTimeout("host=db dbname=events", 5000), "host=db dbname=events statement_timeout=5000")
+}
+
+func TestEmbeddedMigrationsMatchTheDirectory(t *testing.T) {
+	src, err := NewMigrationSource("")
+	assert.NilError(t, err)
+	defer src.Close()
+
+	files, err := filepath.Glob("../../docs/db/migrations/*.up.sql")
+	assert.NilError(t, err)
+
+	count := 0
+	for version, err := src.First(); err == nil; version, err = src.Next(version) {
+		count++
+	}
+	assert.Equal(t, count, len(files))
+}
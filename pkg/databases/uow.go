@@ -0,0 +1,205 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"eventdrivensystem/pkg/errors"
+	"eventdrivensystem/pkg/util"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTxMaxRetries   = 3
+	defaultTxRetryBackoff = 50 * time.Millisecond
+)
+
+type txContextKey struct{}
+
+// Tx is the handle passed to a unit of work
+type Tx interface {
+	// DB returns the gorm transaction, pass it to domain calls through util.DbOptions
+	DB() *gorm.DB
+	// DbOptions is a shortcut for util.DbOptions{Transaction: DB()}
+	DbOptions() util.DbOptions
+	// Context carries the transaction, a UnitOfWork.Do called with it opens a savepoint
+	Context() context.Context
+	// AfterCommit registers fn to run once the outermost transaction has committed
+	AfterCommit(fn func(ctx context.Context))
+}
+
+type UnitOfWork interface {
+	// Do runs fn inside a transaction. It commits when fn returns nil, rolls back
+	// on error or panic (the panic is re-raised) and retries the whole function on
+	// serialization failures and deadlocks, so fn must not keep state between attempts.
+	Do(ctx context.Context, fn func(tx Tx) error, opts ...TxOption) error
+}
+
+type TxOption func(*txConfig)
+
+type txConfig struct {
+	isolation    sql.IsolationLevel
+	readOnly     bool
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+// WithIsolation sets the isolation level of the outermost transaction
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(c *txConfig) {
+		c.isolation = level
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(c *txConfig) {
+		c.readOnly = true
+	}
+}
+
+// WithMaxRetries sets how many times a retryable failure is retried, 0 disables retries
+func WithMaxRetries(n int) TxOption {
+	return func(c *txConfig) {
+		c.maxRetries = n
+	}
+}
+
+type unitOfWork struct {
+	db       *gorm.DB
+	defaults []TxOption
+}
+
+func NewUnitOfWork(db *gorm.DB, defaults ...TxOption) UnitOfWork {
+	return &unitOfWork{
+		db:       db,
+		defaults: defaults,
+	}
+}
+
+type tx struct {
+	db    *gorm.DB
+	ctx   context.Context
+	depth int
+	hooks *[]func(ctx context.Context)
+}
+
+func (t *tx) DB() *gorm.DB {
+	return t.db
+}
+
+func (t *tx) DbOptions() util.DbOptions {
+	return util.DbOptions{Transaction: t.db}
+}
+
+func (t *tx) Context() context.Context {
+	return t.ctx
+}
+
+func (t *tx) AfterCommit(fn func(ctx context.Context)) {
+	*t.hooks = append(*t.hooks, fn)
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(tx Tx) error, opts ...TxOption) error {
+	if parent, ok := ctx.Value(txContextKey{}).(*tx); ok {
+		return u.doSavepoint(parent, fn)
+	}
+
+	cfg := txConfig{
+		isolation:    sql.LevelDefault,
+		maxRetries:   defaultTxMaxRetries,
+		retryBackoff: defaultTxRetryBackoff,
+	}
+	for _, opt := range append(u.defaults, opts...) {
+		opt(&cfg)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var hooks []func(ctx context.Context)
+		hooks, err = u.doOnce(ctx, fn, &cfg)
+		if err == nil {
+			for _, hook := range hooks {
+				hook(ctx)
+			}
+			return nil
+		}
+
+		if attempt >= cfg.maxRetries || !errors.IsRetryable(err) {
+			return err
+		}
+
+		// jittered linear backoff, concurrent retries should not collide again
+		delay := time.Duration(attempt+1)*cfg.retryBackoff + time.Duration(rand.Int63n(int64(cfg.retryBackoff)))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (u *unitOfWork) doOnce(ctx context.Context, fn func(tx Tx) error, cfg *txConfig) (hooks []func(ctx context.Context), err error) {
+	dbTx := u.db.WithContext(ctx).Begin(&sql.TxOptions{
+		Isolation: cfg.isolation,
+		ReadOnly:  cfg.readOnly,
+	})
+	if dbTx.Error != nil {
+		return nil, errors.TranslateSQL(dbTx.Error)
+	}
+
+	t := &tx{db: dbTx, hooks: &hooks}
+	t.ctx = context.WithValue(ctx, txContextKey{}, t)
+
+	defer func() {
+		if r := recover(); r != nil {
+			dbTx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(t); err != nil {
+		dbTx.Rollback()
+		return nil, err
+	}
+
+	if err = dbTx.Commit().Error; err != nil {
+		return nil, errors.TranslateSQL(err)
+	}
+
+	return hooks, nil
+}
+
+// doSavepoint runs a nested unit of work, only the savepoint is rolled back on failure
+func (u *unitOfWork) doSavepoint(parent *tx, fn func(tx Tx) error) (err error) {
+	name := fmt.Sprintf("uow_sp_%d", parent.depth+1)
+	if err = parent.db.SavePoint(name).Error; err != nil {
+		return err
+	}
+
+	var hooks []func(ctx context.Context)
+	t := &tx{db: parent.db, depth: parent.depth + 1, hooks: &hooks}
+	t.ctx = context.WithValue(parent.ctx, txContextKey{}, t)
+
+	defer func() {
+		if r := recover(); r != nil {
+			parent.db.RollbackTo(name)
+			panic(r)
+		}
+	}()
+
+	if err = fn(t); err != nil {
+		parent.db.RollbackTo(name)
+		return err
+	}
+
+	if err = parent.db.Exec("RELEASE SAVEPOINT " + name).Error; err != nil {
+		return err
+	}
+
+	// hooks of a released savepoint belong to the outer transaction now
+	*parent.hooks = append(*parent.hooks, hooks...)
+	return nil
+}
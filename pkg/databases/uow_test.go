@@ -0,0 +1,221 @@
+package databases
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	goErrors "errors"
+	"eventdrivensystem/pkg/errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gotest.tools/assert"
+)
+
+// recorder is a database/sql driver that logs the statements it receives
+// instead of running them, commits fail with the queued errors
+type recorder struct {
+	mu         sync.Mutex
+	log        []string
+	commitErrs []error
+}
+
+func (r *recorder) record(stmt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.log = append(r.log, stmt)
+}
+
+func (r *recorder) statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.log...)
+}
+
+func (r *recorder) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recorderConn{r: r}, nil
+}
+
+func (r *recorder) Driver() driver.Driver {
+	return recorderDriver{r: r}
+}
+
+type recorderDriver struct {
+	r *recorder
+}
+
+func (d recorderDriver) Open(name string) (driver.Conn, error) {
+	return &recorderConn{r: d.r}, nil
+}
+
+type recorderConn struct {
+	r *recorder
+}
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, goErrors.New("prepare is not supported")
+}
+
+func (c *recorderConn) Close() error {
+	return nil
+}
+
+func (c *recorderConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *recorderConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.r.record("BEGIN")
+	return c, nil
+}
+
+func (c *recorderConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recorderConn) Commit() error {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+
+	c.r.log = append(c.r.log, "COMMIT")
+	if len(c.r.commitErrs) > 0 {
+		err := c.r.commitErrs[0]
+		c.r.commitErrs = c.r.commitErrs[1:]
+		return err
+	}
+	return nil
+}
+
+func (c *recorderConn) Rollback() error {
+	c.r.record("ROLLBACK")
+	return nil
+}
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "driver error " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func newRecordedUnitOfWork(t *testing.T, opts ...TxOption) (UnitOfWork, *recorder) {
+	r := &recorder{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(r)}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	assert.NilError(t, err)
+
+	fastRetries := func(c *txConfig) {
+		c.retryBackoff = time.Millisecond
+	}
+	return NewUnitOfWork(db, append([]TxOption{fastRetries}, opts...)...), r
+}
+
+func TestUnitOfWorkRetriesSerializationFailuresAndDeadlocks(t *testing.T) {
+	uow, r := newRecordedUnitOfWork(t)
+	r.commitErrs = []error{sqlStateError(errors.SQLStateDeadlockDetected)}
+
+	calls := 0
+	err := uow.Do(context.Background(), func(tx Tx) error {
+		calls++
+		if calls == 1 {
+			return sqlStateError(errors.SQLStateSerializationFailure)
+		}
+		return nil
+	})
+
+	assert.NilError(t, err)
+	assert.Equal(t, calls, 3)
+	assert.DeepEqual(t, r.statements(), []string{"BEGIN", "ROLLBACK", "BEGIN", "COMMIT", "BEGIN", "COMMIT"})
+}
+
+func TestUnitOfWorkStopsRetrying(t *testing.T) {
+	uow, _ := newRecordedUnitOfWork(t, WithMaxRetries(1))
+
+	calls := 0
+	err := uow.Do(context.Background(), func(tx Tx) error {
+		calls++
+		return sqlStateError(errors.SQLStateSerializationFailure)
+	})
+	assert.Assert(t, errors.IsRetryable(err))
+	assert.Equal(t, calls, 2)
+
+	failed := goErrors.New("not retryable")
+	calls = 0
+	err = uow.Do(context.Background(), func(tx Tx) error {
+		calls++
+		return failed
+	})
+	assert.Equal(t, err, failed)
+	assert.Equal(t, calls, 1)
+}
+
+func TestUnitOfWorkRunsHooksOnlyAfterCommit(t *testing.T) {
+	uow, r := newRecordedUnitOfWork(t)
+
+	failed := goErrors.New("rolled back")
+	err := uow.Do(context.Background(), func(tx Tx) error {
+		tx.AfterCommit(func(ctx context.Context) { r.record("hook") })
+		return failed
+	})
+	assert.Equal(t, err, failed)
+
+	err = uow.Do(context.Background(), func(tx Tx) error {
+		tx.AfterCommit(func(ctx context.Context) { r.record("hook") })
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.statements(), []string{"BEGIN", "ROLLBACK", "BEGIN", "COMMIT", "hook"})
+}
+
+func TestUnitOfWorkSavepointRollbackKeepsOuterTransaction(t *testing.T) {
+	uow, r := newRecordedUnitOfWork(t)
+
+	failed := goErrors.New("step failed")
+	err := uow.Do(context.Background(), func(tx Tx) error {
+		tx.AfterCommit(func(ctx context.Context) { r.record("outer hook") })
+
+		err := uow.Do(tx.Context(), func(inner Tx) error {
+			inner.AfterCommit(func(ctx context.Context) { r.record("rolled back hook") })
+			return failed
+		})
+		assert.Equal(t, err, failed)
+
+		return uow.Do(tx.Context(), func(inner Tx) error {
+			inner.AfterCommit(func(ctx context.Context) { r.record("released hook") })
+			return nil
+		})
+	})
+
+	assert.NilError(t, err)
+	assert.DeepEqual(t, r.statements(), []string{
+		"BEGIN",
+		"SAVEPOINT uow_sp_1",
+		"ROLLBACK TO SAVEPOINT uow_sp_1",
+		"SAVEPOINT uow_sp_1",
+		"RELEASE SAVEPOINT uow_sp_1",
+		"COMMIT",
+		"outer hook",
+		"released hook",
+	})
+}
+
+func TestUnitOfWorkRepanicsAfterRollback(t *testing.T) {
+	uow, r := newRecordedUnitOfWork(t)
+
+	defer func() {
+		assert.Equal(t, recover(), "boom")
+		assert.DeepEqual(t, r.statements(), []string{"BEGIN", "SAVEPOINT uow_sp_1", "ROLLBACK TO SAVEPOINT uow_sp_1", "ROLLBACK"})
+	}()
+
+	uow.Do(context.Background(), func(tx Tx) error {
+		tx.AfterCommit(func(ctx context.Context) { r.record("hook") })
+		return uow.Do(tx.Context(), func(inner Tx) error {
+			panic("boom")
+		})
+	})
+	t.Fatal("the panic was swallowed")
+}
@@ -13,4 +13,12 @@ var (
 	ErrSQLGet          = NewAPIError("ERR1007", http.StatusInternalServerError, "An error occurred while retrieving the requested data. Please try again.")
 	ErrSQLTx           = NewAPIError("ERR1008", http.StatusInternalServerError, "An error occurred while processing the transaction. Please try again.")
 	ErrParseJsonOutbox = NewAPIError("ERR1009", http.StatusInternalServerError, "An error occurred while parsing the JSON data. Please try again.")
+	ErrInvalidLogin    = NewAPIError("ERR1010", http.StatusUnauthorized, "The email or password is incorrect.")
+	ErrInvalidToken    = NewAPIError("ERR1011", http.StatusUnauthorized, "The token is invalid or has expired.")
+	ErrGenerateToken   = NewAPIError("ERR1012", http.StatusInternalServerError, "An error occurred while generating the token. Please try again.")
+	ErrInvalidVerify   = NewAPIError("ERR1013", http.StatusBadRequest, "The verification link is invalid or has expired.")
+	ErrAlreadyVerified = NewAPIError("ERR1014", http.StatusConflict, "The email address has already been verified.")
+	ErrTooManyRequests = NewAPIError("ERR1015", http.StatusTooManyRequests, "Too many requests. Please wait before trying again.")
+	ErrInvalidReset    = NewAPIError("ERR1016", http.StatusBadRequest, "The password reset link is invalid or has expired.")
+	ErrForbidden       = NewAPIError("ERR1017", http.StatusForbidden, "You are not allowed to perform this action.")
 )
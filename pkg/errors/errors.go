@@ -1,6 +1,7 @@
 package errors
 
 import (
+	goErrors "errors"
 	"eventdrivensystem/internal/generated/api_models"
 	"fmt"
 	"net/http"
@@ -41,11 +42,15 @@ func NewHTTPError(c echo.Context, err error) error {
 		return echo.NewHTTPError(http.StatusBadRequest, resp)
 	}
 
-	if apiErr, ok := err.(*APIError); ok {
+	var apiErr *APIError
+	if goErrors.As(TranslateSQL(err), &apiErr) {
 		resp := api_models.ErrorAPIResponse{
 			ErrCode: apiErr.ErrCode,
 			Message: apiErr.Message,
 		}
+		if apiErr == ErrRetryable {
+			c.Response().Header().Set("Retry-After", "1")
+		}
 		return echo.NewHTTPError(apiErr.HTTPStatus, resp)
 	}
 
@@ -0,0 +1,85 @@
+package errors
+
+import (
+	goErrors "errors"
+	"net/http"
+)
+
+// Postgres SQLSTATE codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	SQLStateUniqueViolation      = "23505"
+	SQLStateForeignKeyViolation  = "23503"
+	SQLStateSerializationFailure = "40001"
+	SQLStateDeadlockDetected     = "40P01"
+)
+
+// Sentinels for database failures, match them with errors.Is
+var (
+	ErrConflict      = NewAPIError("ERR_CONFLICT", http.StatusConflict, "The resource already exists.")
+	ErrUnprocessable = NewAPIError("ERR_UNPROCESSABLE", http.StatusUnprocessableEntity, "The request references a resource that does not exist.")
+	ErrRetryable     = NewAPIError("ERR_RETRYABLE", http.StatusServiceUnavailable, "The request conflicted with a concurrent update. Please retry.")
+
+	ErrEmailExists = NewAPIError("ERR_CONFLICT", http.StatusConflict, "The email address is already registered.")
+)
+
+// sqlStater is implemented by both pgconn.PgError and pq.Error, so callers
+// never have to import a driver to classify an error
+type sqlStater interface {
+	SQLState() string
+}
+
+// SQLError keeps the driver error for logging while unwrapping to one of the sentinels above
+type SQLError struct {
+	SQLState string
+	Sentinel *APIError
+	Err      error
+}
+
+func (e *SQLError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SQLError) Unwrap() []error {
+	return []error{e.Sentinel, e.Err}
+}
+
+// TranslateSQL maps a driver error onto the typed sentinels. Errors that are
+// not recognised, including nil, are returned untouched.
+func TranslateSQL(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var sqlErr *SQLError
+	if goErrors.As(err, &sqlErr) {
+		return err
+	}
+
+	var stater sqlStater
+	if !goErrors.As(err, &stater) {
+		return err
+	}
+
+	var sentinel *APIError
+	switch stater.SQLState() {
+	case SQLStateUniqueViolation:
+		sentinel = ErrConflict
+	case SQLStateForeignKeyViolation:
+		sentinel = ErrUnprocessable
+	case SQLStateSerializationFailure, SQLStateDeadlockDetected:
+		sentinel = ErrRetryable
+	default:
+		return err
+	}
+
+	return &SQLError{
+		SQLState: stater.SQLState(),
+		Sentinel: sentinel,
+		Err:      err,
+	}
+}
+
+// IsRetryable reports whether the failed operation can be retried as a whole
+func IsRetryable(err error) bool {
+	return goErrors.Is(TranslateSQL(err), ErrRetryable)
+}
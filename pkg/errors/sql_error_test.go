@@ -0,0 +1,52 @@
+package errors_test
+
+import (
+	goErrors "errors"
+	"fmt"
+	"testing"
+
+	"eventdrivensystem/pkg/errors"
+
+	"gotest.tools/assert"
+)
+
+type fakeDriverError struct {
+	code string
+}
+
+func (e *fakeDriverError) Error() string    { return "driver error " + e.code }
+func (e *fakeDriverError) SQLState() string { return e.code }
+
+func TestTranslateSQL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		in       error
+		expected *errors.APIError
+	}{
+		{name: "unique violation", in: &fakeDriverError{code: errors.SQLStateUniqueViolation}, expected: errors.ErrConflict},
+		{name: "foreign key violation", in: &fakeDriverError{code: errors.SQLStateForeignKeyViolation}, expected: errors.ErrUnprocessable},
+		{name: "serialization failure", in: &fakeDriverError{code: errors.SQLStateSerializationFailure}, expected: errors.ErrRetryable},
+		{name: "deadlock", in: &fakeDriverError{code: errors.SQLStateDeadlockDetected}, expected: errors.ErrRetryable},
+		{name: "wrapped driver error", in: fmt.Errorf("insert user: %w", &fakeDriverError{code: errors.SQLStateUniqueViolation}), expected: errors.ErrConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := errors.TranslateSQL(tc.in)
+
+			assert.Assert(t, goErrors.Is(err, tc.expected))
+			assert.Assert(t, goErrors.Is(err, tc.in))
+			assert.Equal(t, err.Error(), tc.in.Error())
+		})
+	}
+}
+
+func TestTranslateSQLPassthrough(t *testing.T) {
+	plain := goErrors.New("connection refused")
+
+	assert.Equal(t, errors.TranslateSQL(nil), nil)
+	assert.Equal(t, errors.TranslateSQL(plain), plain)
+	assert.Equal(t, errors.TranslateSQL(&fakeDriverError{code: "42P01"}).Error(), "driver error 42P01")
+	assert.Assert(t, !errors.IsRetryable(plain))
+	assert.Assert(t, errors.IsRetryable(&fakeDriverError{code: errors.SQLStateDeadlockDetected}))
+}
@@ -0,0 +1,166 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+
+	// DefaultCheckTimeout bounds a single check, a check that takes longer is DOWN
+	DefaultCheckTimeout = 2 * time.Second
+)
+
+// Check returns nil when the component is usable
+type Check func(ctx context.Context) error
+
+type ComponentStatus struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components,omitempty"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks of a process
+type Checker struct {
+	checks  []namedCheck
+	timeout time.Duration
+}
+
+func NewChecker() *Checker {
+	return &Checker{timeout: DefaultCheckTimeout}
+}
+
+func (c *Checker) Add(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Run runs every check concurrently, the report is UP when all components are
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Components: make(map[string]ComponentStatus, len(c.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			status := run(ctx, nc.check, c.timeout)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[nc.name] = status
+			if status.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(nc)
+	}
+	wg.Wait()
+
+	return report
+}
+
+func run(ctx context.Context, check Check, timeout time.Duration) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() { errCh <- check(ctx) }()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	status := ComponentStatus{
+		Status:    StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		status.Status = StatusDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+// RegisterRoutes adds GET /healthz, which answers as long as the process serves
+// HTTP, and GET /readyz, which is 503 while any check fails
+func RegisterRoutes(e *echo.Echo, c *Checker) {
+	e.GET("/healthz", func(ctx echo.Context) error {
+		return ctx.JSON(http.StatusOK, Report{Status: StatusUp})
+	})
+
+	e.GET("/readyz", func(ctx echo.Context) error {
+		report := c.Run(ctx.Request().Context())
+		if report.Status != StatusUp {
+			return ctx.JSON(http.StatusServiceUnavailable, report)
+		}
+		return ctx.JSON(http.StatusOK, report)
+	})
+}
+
+// Database pings the connection pool of db
+func Database(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Redis pings the redis behind an asynq client
+func Redis(client *asynq.Client) Check {
+	return func(ctx context.Context) error {
+		return client.Ping()
+	}
+}
+
+// Heartbeat records when a loop last made progress
+type Heartbeat struct {
+	last atomic.Int64
+}
+
+func (h *Heartbeat) Beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// Check is DOWN until the first beat and when the last one is older than maxAge
+func (h *Heartbeat) Check(maxAge func() time.Duration) Check {
+	return func(ctx context.Context) error {
+		last := h.last.Load()
+		if last == 0 {
+			return fmt.Errorf("no heartbeat yet")
+		}
+
+		if age := time.Since(time.Unix(0, last)); age > maxAge() {
+			return fmt.Errorf("last heartbeat %s ago", age.Round(time.Second))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	goErrors "errors"
+	"eventdrivensystem/pkg/health"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gotest.tools/assert"
+)
+
+func TestCheckerReportsEveryComponent(t *testing.T) {
+	checker := health.NewChecker()
+	checker.Add("postgres", func(ctx context.Context) error { return nil })
+	checker.Add("redis", func(ctx context.Context) error { return goErrors.New("connection refused") })
+
+	report := checker.Run(context.Background())
+
+	assert.Equal(t, report.Status, health.StatusDown)
+	assert.Equal(t, report.Components["postgres"].Status, health.StatusUp)
+	assert.Equal(t, report.Components["redis"].Status, health.StatusDown)
+	assert.Equal(t, report.Components["redis"].Error, "connection refused")
+}
+
+func TestHeartbeat(t *testing.T) {
+	hb := &health.Heartbeat{}
+	maxAge := time.Minute
+	check := hb.Check(func() time.Duration { return maxAge })
+
+	assert.ErrorContains(t, check(context.Background()), "no heartbeat yet")
+
+	hb.Beat()
+	assert.NilError(t, check(context.Background()))
+
+	maxAge = -time.Second
+	assert.ErrorContains(t, check(context.Background()), "last heartbeat")
+}
+
+func TestReadyzIsUnavailableWhileACheckFails(t *testing.T) {
+	e := echo.New()
+	checker := health.NewChecker()
+	checker.Add("relay", func(ctx context.Context) error { return goErrors.New("stuck") })
+	health.RegisterRoutes(e, checker)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, rec.Code, http.StatusServiceUnavailable)
+
+	var report health.Report
+	assert.NilError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, report.Components["relay"].Error, "stuck")
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, rec.Code, http.StatusOK)
+}
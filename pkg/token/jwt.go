@@ -0,0 +1,63 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidJWT = errors.New("invalid jwt")
+
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// NewJWT signs an HS256 access token for the given subject
+func NewJWT(secret, issuer, subject string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    issuer,
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
+}
+
+// ParseJWT verifies the signature, expiry and issuer of an access token
+func ParseJWT(secret, issuer, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidJWT, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidJWT)
+	}
+
+	return claims, nil
+}